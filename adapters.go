@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"io"
+	"log"
+	"strings"
+)
+
+// levelWriter is the io.Writer returned by Logger.Writer. depth is the number of extra stack frames
+// between the caller and Write, used to find the file/line combo that created the entry.
+type levelWriter struct {
+	logger *Logger
+	level  Level
+	depth  int
+}
+
+// Writer returns an io.Writer that logs everything written to it at the specified Level, one entry per
+// line. It is meant for bridging libraries that only know how to write to an io.Writer. Empty lines are
+// dropped, and entries logged at WarnLvl or ErrorLvl are sent to Sentry, if Sentry has been configured.
+func (l *Logger) Writer(level Level) io.Writer {
+	return levelWriter{logger: l, level: level}
+}
+
+// Write logs each line of p as a separate entry. It always reports all of p as written, unless writing
+// to the Logger's output fails, in which case it reports the bytes of the lines logged before the one
+// that failed.
+func (w levelWriter) Write(p []byte) (int, error) {
+	l := w.logger
+	if !l.enabled(w.level) {
+		return len(p), nil
+	}
+	written := 0
+	for _, chunk := range strings.SplitAfter(string(p), "\n") {
+		line := strings.TrimSuffix(strings.TrimSuffix(chunk, "\n"), "\r")
+		if line != "" {
+			if err := l.output(l.calldepth+w.depth+2, line, w.level); err != nil {
+				return written, err
+			}
+			if w.level == WarnLvl || w.level == ErrorLvl {
+				l.toSentry("%s", []interface{}{line}, w.level, nil)
+			}
+		}
+		written += len(chunk)
+	}
+	return written, nil
+}
+
+// StdLogger returns a *log.Logger from the standard library that logs everything written to it through
+// the Logger at the specified Level. It is meant for dependencies that accept a *log.Logger, like
+// net/http.Server's ErrorLog. The returned *log.Logger has no prefix or flags set, as the Logger adds its
+// own header to each entry.
+func (l *Logger) StdLogger(level Level) *log.Logger {
+	return log.New(levelWriter{logger: l, level: level, depth: 2}, "", 0)
+}
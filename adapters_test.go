@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	w := log.Writer(WarnLvl)
+	n, err := w.Write([]byte("first line\nsecond line\r\n\nthird line"))
+	if err != nil {
+		t.Errorf("Unexpected error: %+v\n", err)
+	}
+	if n != 35 {
+		t.Errorf("Expected to write 35 bytes, wrote %d instead\n", n)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	expected := []string{"first line", "second line", "third line"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d instead: '%s'\n", len(expected), len(lines), buf.String())
+	}
+	for pos, line := range lines {
+		if !strings.Contains(line, "[WARN] ") || !strings.HasSuffix(line, ": "+expected[pos]) {
+			t.Errorf("Expected entry %d to be a WARN of '%s', got '%s' instead\n", pos, expected[pos], line)
+		}
+		if !strings.Contains(line, "adapters_test.go:") {
+			t.Errorf("Expected caller to be adapters_test.go, got '%s'\n", line)
+		}
+	}
+
+	buf.Reset()
+	fmt.Fprintln(log.Writer(DebugLvl), "suppressed")
+	if buf.Len() != 0 {
+		t.Errorf("Expected DEBUG entries to be suppressed, got '%s'\n", buf.String())
+	}
+}
+
+// failAfterWriter accepts n writes, and fails every write after them.
+type failAfterWriter struct {
+	n   int
+	err error
+}
+
+func (w *failAfterWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		return 0, w.err
+	}
+	w.n--
+	return len(p), nil
+}
+
+func TestWriterPartial(t *testing.T) {
+	log, err := New(InfoLvl, &failAfterWriter{n: 1, err: errors.New("disk full")}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	n, err := log.Writer(InfoLvl).Write([]byte("first line\r\nsecond line\n"))
+	if err == nil {
+		t.Error("Expected an error writing the second line, got nil instead")
+	}
+	if n != 12 {
+		t.Errorf("Expected to write 12 bytes, wrote %d instead\n", n)
+	}
+}
+
+func TestWriterSentry(t *testing.T) {
+	log, transport := newSentryTestLogger(t)
+	fmt.Fprintln(log.Writer(ErrorLvl), "100% done")
+	log.FlushSentry(time.Second)
+	if len(transport.packets) != 1 || transport.packets[0].Message != "100% done" {
+		t.Errorf("Expected '100%% done' to be sent as is, got %+v instead\n", transport.packets)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	std := log.StdLogger(InfoLvl)
+	if std.Flags() != 0 {
+		t.Errorf("Expected flags to be 0, got %d instead\n", std.Flags())
+	}
+	std.Printf("Test %s", "output")
+	out := buf.String()
+	if strings.Count(out, "\n") != 1 {
+		t.Errorf("Expected a single entry, got '%s'\n", out)
+	}
+	if !strings.Contains(out, "[INFO] ") || !strings.HasSuffix(out, ": Test output\n") {
+		t.Errorf("Expected an INFO entry of 'Test output', got '%s' instead\n", out)
+	}
+	if !strings.Contains(out, "adapters_test.go:") {
+		t.Errorf("Expected caller to be adapters_test.go, got '%s'\n", out)
+	}
+}
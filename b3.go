@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+const b3Key = "github.com/DramaFever/go-logging#b3"
+
+// ContextWithB3 returns a copy of ctx holding the trace and span IDs from the Zipkin B3 headers in h, so
+// a Logger returned by WithContext logs them. If h holds no valid B3 IDs, ctx is returned unchanged.
+func ContextWithB3(ctx context.Context, h http.Header) context.Context {
+	traceID, spanID, ok := ParseB3(h)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, b3Key, traceIDs{traceID: traceID, spanID: spanID})
+}
+
+// WithB3 returns a child of the Logger with the trace and span IDs from the Zipkin B3 headers in h as the
+// trace_id and span_id fields. If h holds no valid B3 IDs, the Logger itself is returned.
+func (l *Logger) WithB3(h http.Header) *Logger {
+	traceID, spanID, ok := ParseB3(h)
+	if !ok {
+		return l
+	}
+	return l.Child(Fields{TraceIDField: traceID, SpanIDField: spanID})
+}
+
+// ParseB3 returns the trace and span IDs from the Zipkin B3 headers in h. Both the single b3 header, like
+// "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1", and the X-B3-TraceId and X-B3-SpanId headers
+// are understood; the single header is used if there are both. ok is false if h holds no valid IDs,
+// including a b3 header that only carries a sampling decision.
+func ParseB3(h http.Header) (traceID, spanID string, ok bool) {
+	if single := h.Get("b3"); single != "" {
+		parts := strings.Split(single, "-")
+		if len(parts) < 2 {
+			return "", "", false
+		}
+		traceID, spanID = parts[0], parts[1]
+	} else {
+		traceID, spanID = h.Get("X-B3-TraceId"), h.Get("X-B3-SpanId")
+	}
+	traceID, spanID = strings.ToLower(traceID), strings.ToLower(spanID)
+	if !isLowerHex(traceID, 32) && !isLowerHex(traceID, 16) || !isLowerHex(spanID, 16) {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// b3Fields adds the B3 IDs in ctx, if there are any, to fields, unless they already hold a trace ID.
+func b3Fields(ctx context.Context, fields Fields) {
+	ids, ok := ctx.Value(b3Key).(traceIDs)
+	if !ok {
+		return
+	}
+	if _, ok := fields[TraceIDField]; ok {
+		return
+	}
+	fields[TraceIDField] = ids.traceID
+	fields[SpanIDField] = ids.spanID
+}
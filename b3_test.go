@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestParseB3(t *testing.T) {
+	parseTests := []struct {
+		header          http.Header
+		traceID, spanID string
+		ok              bool
+	}{
+		{http.Header{"B3": {"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90"}},
+			"80f198ee56343ba864fe8b2a57d3eff7", "e457b5a2e4d86bd1", true},
+		{http.Header{"B3": {"64fe8b2a57d3eff7-e457b5a2e4d86bd1"}}, "64fe8b2a57d3eff7", "e457b5a2e4d86bd1", true},
+		{http.Header{"X-B3-Traceid": {"80F198EE56343BA864FE8B2A57D3EFF7"}, "X-B3-Spanid": {"e457b5a2e4d86bd1"}},
+			"80f198ee56343ba864fe8b2a57d3eff7", "e457b5a2e4d86bd1", true},
+		{http.Header{"B3": {"64fe8b2a57d3eff7-e457b5a2e4d86bd1"}, "X-B3-Traceid": {"80f198ee56343ba864fe8b2a57d3eff7"},
+			"X-B3-Spanid": {"05e3ac9a4f6e3b90"}}, "64fe8b2a57d3eff7", "e457b5a2e4d86bd1", true},
+		{http.Header{}, "", "", false},
+		{http.Header{"B3": {"1"}}, "", "", false},
+		{http.Header{"B3": {"80f198ee56343ba8-nothex"}}, "", "", false},
+		{http.Header{"X-B3-Traceid": {"80f198ee56343ba864fe8b2a57d3eff7"}}, "", "", false},
+	}
+	for _, test := range parseTests {
+		traceID, spanID, ok := ParseB3(test.header)
+		if traceID != test.traceID || spanID != test.spanID || ok != test.ok {
+			t.Errorf("Expected %v to parse to %q, %q, %t, got %q, %q, %t instead\n", test.header, test.traceID,
+				test.spanID, test.ok, traceID, spanID, ok)
+		}
+	}
+}
+
+func TestWithB3(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if got := log.WithB3(http.Header{}); got != log {
+		t.Error("Expected the Logger itself for headers without B3 IDs")
+	}
+
+	h := http.Header{}
+	h.Set("X-B3-TraceId", "80f198ee56343ba864fe8b2a57d3eff7")
+	h.Set("X-B3-SpanId", "e457b5a2e4d86bd1")
+	log.WithB3(h).Info("multi")
+	if !strings.HasSuffix(buf.String(), "multi span_id=e457b5a2e4d86bd1 trace_id=80f198ee56343ba864fe8b2a57d3eff7\n") {
+		t.Errorf("Expected B3 fields, got %q instead\n", buf.String())
+	}
+
+	buf.Reset()
+	ctx := ContextWithB3(context.Background(), http.Header{"B3": {"64fe8b2a57d3eff7-e457b5a2e4d86bd1-d"}})
+	log.WithContext(ctx).Info("single")
+	if !strings.HasSuffix(buf.String(), "single span_id=e457b5a2e4d86bd1 trace_id=64fe8b2a57d3eff7\n") {
+		t.Errorf("Expected B3 fields, got %q instead\n", buf.String())
+	}
+
+	buf.Reset()
+	ctx = ContextWithTraceParent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	log.WithContext(ctx).Info("both")
+	if !strings.HasSuffix(buf.String(), "both span_id=00f067aa0ba902b7 trace_id=4bf92f3577b34da6a3ce929d0e0e4736\n") {
+		t.Errorf("Expected the traceparent to take precedence, got %q instead\n", buf.String())
+	}
+}
@@ -0,0 +1,194 @@
+package logging
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBatchSize is the size a BatchWriter's buffer reaches before it is written, when none is given.
+	DefaultBatchSize = 32 << 10
+	// DefaultBatchInterval is the longest a BatchWriter holds on to output, when no interval is given.
+	DefaultBatchInterval = 100 * time.Millisecond
+)
+
+// BatchWriter is an io.WriteCloser that coalesces many small writes, like log lines, into fewer large
+// writes to the io.Writer it wraps, so a busy Logger writing to a file or socket makes far fewer system
+// calls. Output is written once the buffer reaches the batch size, or once the oldest buffered output is
+// as old as the batch interval, whichever comes first. Writes still happen on the caller's goroutine,
+// except for those made when the interval passes. It is safe for concurrent use.
+//
+// A Logger writing to a BatchWriter drains it when the Logger's Flush or Close method is called. An
+// error writing a batch from the timer is returned by the next call to Write or Flush.
+type BatchWriter struct {
+	out      io.Writer
+	size     int
+	interval time.Duration
+
+	mu    sync.Mutex
+	buf   []byte
+	timer *time.Timer
+	err   error
+}
+
+// NewBatchWriter returns a BatchWriter that writes to out in batches of up to size bytes, holding on to
+// output for at most interval. If size or interval is zero or less, DefaultBatchSize or
+// DefaultBatchInterval is used.
+func NewBatchWriter(out io.Writer, size int, interval time.Duration) *BatchWriter {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	if interval <= 0 {
+		interval = DefaultBatchInterval
+	}
+	return &BatchWriter{out: out, size: size, interval: interval}
+}
+
+// Write adds p to the batch, writing the batch out if it is full.
+func (b *BatchWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.takeErr(); err != nil {
+		return 0, err
+	}
+	if len(b.buf) > 0 && len(b.buf)+len(p) > b.size {
+		if err := b.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if len(p) >= b.size {
+		_, err := b.out.Write(p)
+		if err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	b.buf = append(b.buf, p...)
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.interval, b.expire)
+	}
+	return len(p), nil
+}
+
+// Flush writes out the current batch, and flushes the wrapped io.Writer too if it has a Flush method.
+func (b *BatchWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := combineErrors(b.takeErr(), b.flush())
+	if flusher, ok := b.out.(interface {
+		Flush() error
+	}); ok {
+		err = combineErrors(err, flusher.Flush())
+	}
+	return err
+}
+
+// Close writes out the current batch, and closes the wrapped io.Writer if it is an io.Closer.
+func (b *BatchWriter) Close() error {
+	err := b.Flush()
+	if closer, ok := b.out.(io.Closer); ok {
+		err = combineErrors(err, closer.Close())
+	}
+	return err
+}
+
+// SetFlushInterval changes the longest the BatchWriter holds on to output, starting with the next batch.
+// If interval is zero or less, DefaultBatchInterval is used.
+func (b *BatchWriter) SetFlushInterval(interval time.Duration) *BatchWriter {
+	if interval <= 0 {
+		interval = DefaultBatchInterval
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.interval = interval
+	return b
+}
+
+// expire writes out the current batch once it is as old as the interval, and flushes the wrapped
+// io.Writer if it has a Flush method, so output buffered there, like by a GzipFile, isn't held longer.
+func (b *BatchWriter) expire() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.timer = nil
+	err := b.flush()
+	if flusher, ok := b.out.(interface {
+		Flush() error
+	}); ok && err == nil {
+		err = flusher.Flush()
+	}
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+}
+
+// SetFlushInterval buffers the Logger's output in a BatchWriter, which writes it once DefaultBatchSize
+// bytes are buffered, or once the oldest buffered output is as old as interval, whichever comes first.
+// This makes far fewer system calls than writing every line as it is logged, while bounding how long a
+// line can sit in memory, and so be lost if the process crashes. If the output is already a BatchWriter,
+// its interval is changed instead. If interval is zero or less, DefaultBatchInterval is used.
+//
+// The Logger's Flush and Close methods write out the buffered output, then flush or close the output it
+// wraps, like a ReopeningFile or GzipFile. If the Logger writes to Sinks, each one is buffered separately.
+// Outputs that are LevelWriters, like syslog, or EntryWriters, like the journal or a UDP NetWriter, are
+// left unbuffered, since a BatchWriter would lose the Level of each entry, or join entries into one
+// message.
+//
+// Like SetOutput, it only changes the output of this Logger, and of Loggers derived from it afterwards.
+// Loggers derived earlier, with Child, Clone, and the like, keep writing straight to the unbuffered
+// output, so their lines can arrive ahead of buffered lines logged before them. Call SetFlushInterval
+// before deriving Loggers, or pass WithOutput a BatchWriter, so they all share the buffer.
+func (l *Logger) SetFlushInterval(interval time.Duration) *Logger {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	if s, ok := l.out.(sinks); ok {
+		buffered := make(sinks, len(s))
+		for i, sink := range s {
+			sink.Writer = withFlushInterval(sink.Writer, interval)
+			buffered[i] = sink
+		}
+		l.setOutput(buffered)
+		return l
+	}
+	l.setOutput(withFlushInterval(l.out, interval))
+	return l
+}
+
+// withFlushInterval returns out buffered by a BatchWriter with the given interval, or out itself, with
+// its interval changed, if it already is one. Discard, LevelWriters, and EntryWriters are returned as is.
+func withFlushInterval(out io.Writer, interval time.Duration) io.Writer {
+	if b, ok := out.(*BatchWriter); ok {
+		return b.SetFlushInterval(interval)
+	}
+	if out == nil || out == Discard {
+		return out
+	}
+	if _, ok := out.(LevelWriter); ok {
+		return out
+	}
+	if e, ok := out.(EntryWriter); ok && e.WritesEntries() {
+		return out
+	}
+	return NewBatchWriter(out, 0, interval)
+}
+
+// flush writes the buffer to the wrapped io.Writer, and empties it. The caller must hold b.mu.
+func (b *BatchWriter) flush() error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.buf) == 0 {
+		return nil
+	}
+	_, err := b.out.Write(b.buf)
+	b.buf = b.buf[:0]
+	return err
+}
+
+// takeErr returns the error from the last timed write, if any, and clears it. The caller must hold b.mu.
+func (b *BatchWriter) takeErr() error {
+	err := b.err
+	b.err = nil
+	return err
+}
@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeRecorder is an io.Writer that records each call to Write separately.
+type writeRecorder struct {
+	sync.Mutex
+	writes []string
+	err    error
+}
+
+func (w *writeRecorder) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *writeRecorder) all() []string {
+	w.Lock()
+	defer w.Unlock()
+	return append([]string(nil), w.writes...)
+}
+
+func TestBatchWriterSize(t *testing.T) {
+	out := &writeRecorder{}
+	log, err := NewWithOptions(WithOutput(NewBatchWriter(out, 20, time.Hour)), WithFormatter(upperFormatter{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log.Info("a")
+	log.Info("b")
+	if writes := out.all(); len(writes) != 0 {
+		t.Errorf("Expected output to be batched, got %q instead\n", writes)
+	}
+	log.Info("c")
+	log.Info("a line longer than the batch")
+	log.Info("d")
+	if err := log.Close(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	expected := []string{"INFO: a\nINFO: b\n", "INFO: c\n", "INFO: a line longer than the batch\n", "INFO: d\n"}
+	if writes := out.all(); !reflect.DeepEqual(writes, expected) {
+		t.Errorf("Expected %q, got %q instead\n", expected, writes)
+	}
+}
+
+func TestBatchWriterInterval(t *testing.T) {
+	out := &writeRecorder{}
+	b := NewBatchWriter(out, 0, 10*time.Millisecond)
+	b.Write([]byte("one\n"))
+	b.Write([]byte("two\n"))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(out.all()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if writes := out.all(); len(writes) != 1 || writes[0] != "one\ntwo\n" {
+		t.Errorf("Expected the batch to be written after the interval, got %q instead\n", writes)
+	}
+}
+
+func TestBatchWriterTimerError(t *testing.T) {
+	writeErr := errors.New("disk full")
+	out := &writeRecorder{err: writeErr}
+	b := NewBatchWriter(out, 0, time.Millisecond)
+	b.Write([]byte("lost\n"))
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		b.mu.Lock()
+		pending := len(b.buf)
+		b.mu.Unlock()
+		if pending == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := b.Write([]byte("next\n")); err != writeErr {
+		t.Errorf("Expected %+v, got %+v instead\n", writeErr, err)
+	}
+}
+
+func TestSetFlushInterval(t *testing.T) {
+	out := &writeRecorder{}
+	log, err := NewWithOptions(WithOutput(out), WithFormatter(upperFormatter{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log.SetFlushInterval(time.Hour)
+	b, ok := log.out.(*BatchWriter)
+	if !ok {
+		t.Fatalf("Expected the output to be buffered, got %T instead\n", log.out)
+	}
+	log.Info("a")
+	log.Info("b")
+	if writes := out.all(); len(writes) != 0 {
+		t.Errorf("Expected output to be buffered, got %q instead\n", writes)
+	}
+	log.SetFlushInterval(10 * time.Millisecond)
+	if log.out != b {
+		t.Errorf("Expected the BatchWriter's interval to be changed, got a new %T instead\n", log.out)
+	}
+	log.Info("c")
+	if err := log.Close(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	expected := []string{"INFO: a\nINFO: b\nINFO: c\n"}
+	if writes := out.all(); !reflect.DeepEqual(writes, expected) {
+		t.Errorf("Expected Close to write the buffer, got %q instead\n", writes)
+	}
+
+	text, structured := &writeRecorder{}, &writeRecorder{}
+	log, err = NewWithOptions(WithSinks(Sink{Writer: text}, Sink{Writer: structured, Formatter: upperFormatter{}}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log.SetFlushInterval(10 * time.Millisecond)
+	log.Info("d")
+	deadline := time.Now().Add(5 * time.Second)
+	for len(structured.all()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if writes := structured.all(); !reflect.DeepEqual(writes, []string{"INFO: d\n"}) {
+		t.Errorf("Expected the sink to be written after the interval, got %q instead\n", writes)
+	}
+	log.Close()
+	if writes := text.all(); len(writes) != 1 {
+		t.Errorf("Expected the other sink to be written too, got %q instead\n", writes)
+	}
+}
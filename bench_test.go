@@ -0,0 +1,131 @@
+package logging
+
+import (
+	"io/ioutil"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func newBenchmarkLogger(b *testing.B) *Logger {
+	log, err := New(DebugLvl, ioutil.Discard, "", nil)
+	if err != nil {
+		b.Fatal("Unexpected error:", err)
+	}
+	return log
+}
+
+// BenchmarkConcurrentOutput measures 8 goroutines logging to the same Logger at once.
+func BenchmarkConcurrentOutput(b *testing.B) {
+	const emitters = 8
+	log := newBenchmarkLogger(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	var wg sync.WaitGroup
+	for i := 0; i < emitters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < b.N/emitters; j++ {
+				log.Infof("request %d served in %dms", j, 42)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// BenchmarkSuppressedDebugf measures Debugf calls on a Logger that doesn't include DebugLvl.
+func BenchmarkSuppressedDebugf(b *testing.B) {
+	log := newBenchmarkLogger(b).SetLevel(InfoLvl)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Debugf("cache miss for %s", "key")
+		}
+	})
+}
+
+// BenchmarkInfo measures the fast path for messages that are a single string.
+func BenchmarkInfo(b *testing.B) {
+	log := newBenchmarkLogger(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Info("request served")
+	}
+}
+
+// BenchmarkInfoMultipleArgs measures messages that need fmt to join their arguments.
+func BenchmarkInfoMultipleArgs(b *testing.B) {
+	log := newBenchmarkLogger(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Info("request served in", 42, "ms")
+	}
+}
+
+// BenchmarkInfof measures messages interpolated from a format string.
+func BenchmarkInfof(b *testing.B) {
+	log := newBenchmarkLogger(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Infof("request %d served in %dms", i, 42)
+	}
+}
+
+// BenchmarkSuppressedDebug measures the level check alone, for Debug calls on a Logger that doesn't
+// include DebugLvl.
+func BenchmarkSuppressedDebug(b *testing.B) {
+	log := newBenchmarkLogger(b).SetLevel(InfoLvl)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Debug("cache miss")
+	}
+}
+
+// BenchmarkConcurrent measures as many goroutines as GOMAXPROCS logging through one Logger with Fields,
+// which contend for its lock and the buffer pool.
+func BenchmarkConcurrent(b *testing.B) {
+	log := newBenchmarkLogger(b).Child(Fields{"service": "api", "region": "us-east-1"})
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Info("request served")
+		}
+	})
+}
+
+// BenchmarkInfow measures messages with typed Fields.
+func BenchmarkInfow(b *testing.B) {
+	log := newBenchmarkLogger(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Infow("request served", String("path", "/"), Int("status", 200))
+	}
+}
+
+// BenchmarkJSONFormatter measures messages with Fields rendered by the JSONFormatter.
+func BenchmarkJSONFormatter(b *testing.B) {
+	log := newBenchmarkLogger(b).Child(Fields{"service": "api", "status": 200})
+	log.formatter = JSONFormatter{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log.Info("request served")
+	}
+}
+
+// BenchmarkCaller compares looking up the caller of a single call site with runtime.Caller, which
+// resolves its symbols every time, to callerOf, which caches them.
+func BenchmarkCaller(b *testing.B) {
+	b.Run("runtime.Caller", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			runtime.Caller(0)
+		}
+	})
+	b.Run("callerOf", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			callerOf(0)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"unsafe"
+)
+
+// The Bytes methods log a message that is already in a []byte, like a pre-rendered JSON payload, without
+// copying it into a string first. The caller must not modify the slice until the call returns. Like the
+// other log methods, a trailing newline is added if the message doesn't end with one.
+
+// InfoBytes writes a log entry with the Level of InfoLvl, using b as the message.
+func (l *Logger) InfoBytes(b []byte) {
+	if !l.enabled(InfoLvl) {
+		return
+	}
+	l.logBytes(InfoLvl, b)
+}
+
+// WarnBytes writes a log entry with the Level of WarnLvl, using b as the message.
+//
+// Any message logged with WarnBytes will automatically be sent to Sentry, if Sentry
+// has been configured. Sentry gets its own copy of the message.
+func (l *Logger) WarnBytes(b []byte) {
+	if !l.enabled(WarnLvl) {
+		return
+	}
+	l.logBytes(WarnLvl, b)
+	l.toSentry("%s", []interface{}{string(b)}, WarnLvl, nil)
+}
+
+// ErrorBytes writes a log entry with the Level of ErrorLvl, using b as the message.
+//
+// Any message logged with ErrorBytes will automatically be sent to Sentry, if Sentry
+// has been configured. Sentry gets its own copy of the message.
+func (l *Logger) ErrorBytes(b []byte) {
+	if !l.enabled(ErrorLvl) {
+		return
+	}
+	l.logBytes(ErrorLvl, b)
+	l.toSentry("%s", []interface{}{string(b)}, ErrorLvl, nil)
+}
+
+func (l *Logger) logBytes(lvl Level, b []byte) {
+	err := l.output(l.calldepth+3, unsafeString(b), lvl)
+	if err != nil {
+		l.handleError(err)
+	}
+}
+
+// unsafeString returns a string that shares b's memory, instead of copying it. The string is only valid
+// for as long as b isn't modified, so it must not outlive the call it is made for.
+func unsafeString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBytesMethods(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	payload := []byte(`{"status":"ok"}`)
+	log.DebugBytes(payload)
+	if buf.Len() != 0 {
+		t.Errorf("Expected DEBUG entries to be suppressed, got '%s'\n", buf.String())
+	}
+	for _, f := range []func([]byte){log.InfoBytes, log.WarnBytes, log.ErrorBytes} {
+		buf.Reset()
+		f(payload)
+		if !strings.HasSuffix(buf.String(), `: {"status":"ok"}`+"\n") {
+			t.Errorf("Expected output to end with the payload, got '%s' instead\n", buf.String())
+		}
+		if !strings.Contains(buf.String(), "bytes_test.go:") {
+			t.Errorf("Expected caller to be bytes_test.go, got '%s'\n", buf.String())
+		}
+	}
+}
+
+func TestBytesSentry(t *testing.T) {
+	log, transport := newSentryTestLogger(t)
+	log.ErrorBytes([]byte("100% of 5%d"))
+	log.FlushSentry(time.Second)
+	if len(transport.packets) != 1 || transport.packets[0].Message != "100% of 5%d" {
+		t.Errorf("Expected the bytes to be sent as is, got %+v instead\n", transport.packets)
+	}
+}
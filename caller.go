@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"runtime"
+	"sync"
+)
+
+// maxCachedCallers bounds the number of call sites whose file and line are cached. Programs rarely log
+// from more call sites than this; if one does, the cache is emptied and filled again.
+const maxCachedCallers = 4096
+
+// callerLocation is the file and line of a call site.
+type callerLocation struct {
+	file string
+	line int
+}
+
+// callerCache maps the program counters of call sites to their file and line, so entries logged
+// repeatedly from the same line, like in a loop, don't resolve its symbols every time.
+var callerCache = struct {
+	sync.RWMutex
+	locations map[uintptr]callerLocation
+}{locations: make(map[uintptr]callerLocation)}
+
+// SetCallerLevel makes the Logger only look up the file and line of the caller for entries at level or
+// above, like WarnLvl, so that high-volume entries below it, which are rarely traced back to their source,
+// skip the cost of the lookup. Entries below level are written without a caller, and their Entry.File is
+// empty. Pass DebugLvl to look up the caller of every entry again, which is the default.
+//
+// Entries sent to Sentry still carry a full stack trace, whatever their level.
+func (l *Logger) SetCallerLevel(level Level) *Logger {
+	l.callerLevel = level
+	return l
+}
+
+// callerOf returns the file and line of the caller skip frames above the function calling callerOf,
+// like runtime.Caller, looking them up in callerCache. It returns false if there is no such caller.
+func callerOf(skip int) (string, int, bool) {
+	var pc [1]uintptr
+	// Skip runtime.Callers and callerOf itself.
+	if runtime.Callers(skip+2, pc[:]) == 0 {
+		return "", 0, false
+	}
+	return callerAt(pc[0])
+}
+
+// callerAt returns the file and line of the call site with program counter pc, looking them up in
+// callerCache. It returns false if pc is 0.
+func callerAt(pc uintptr) (string, int, bool) {
+	if pc == 0 {
+		return "", 0, false
+	}
+	callerCache.RLock()
+	loc, ok := callerCache.locations[pc]
+	callerCache.RUnlock()
+	if ok {
+		return loc.file, loc.line, true
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	loc = callerLocation{file: frame.File, line: frame.Line}
+	callerCache.Lock()
+	if len(callerCache.locations) >= maxCachedCallers {
+		callerCache.locations = make(map[uintptr]callerLocation)
+	}
+	callerCache.locations[pc] = loc
+	callerCache.Unlock()
+	return loc.file, loc.line, true
+}
@@ -0,0 +1,51 @@
+//go:build log_debug || !log_nodebug
+// +build log_debug !log_nodebug
+
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSetCallerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetCallerLevel(WarnLvl)
+
+	log.Info("no caller")
+	if !strings.HasSuffix(buf.String(), " [INFO] no caller\n") {
+		t.Errorf("Expected an entry without a caller, got '%s' instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log.Warn("with caller")
+	if !strings.Contains(buf.String(), "caller_test.go:") {
+		t.Errorf("Expected an entry with a caller, got '%s' instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log.formatter = JSONFormatter{}
+	log.Info("no caller")
+	if strings.Contains(buf.String(), `"caller"`) {
+		t.Errorf("Expected a JSON entry without a caller, got '%s' instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log.formatter = StackdriverFormatter{}
+	log.Info("no caller")
+	if strings.Contains(buf.String(), "sourceLocation") || !strings.HasSuffix(buf.String(), "}\n") {
+		t.Errorf("Expected a Cloud Logging entry without a source location, got '%s' instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log.formatter = TextFormatter{}
+	log.SetCallerLevel(DebugLvl).Debug("with caller")
+	if !strings.Contains(buf.String(), "caller_test.go:") {
+		t.Errorf("Expected an entry with a caller, got '%s' instead\n", buf.String())
+	}
+}
@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// LogFormatEnv is the environment variable that overrides the format NewAuto chooses. Set it to "json"
+// or "console".
+const LogFormatEnv = "LOG_FORMAT"
+
+// levelColors are the ANSI SGR codes used to color each Level in the header.
+var levelColors = map[Level]string{
+	DebugLvl: "\x1b[90m",
+	InfoLvl:  "\x1b[36m",
+	WarnLvl:  "\x1b[33m",
+	ErrorLvl: "\x1b[31m",
+}
+
+const colorReset = "\x1b[0m"
+
+// colorEnabled reports whether output written to out should be colored. Every colored code path uses it,
+// so they agree. The signals are checked in this order, and the first that applies wins:
+//
+//  1. NO_COLOR set to any non-empty value disables color (https://no-color.org).
+//  2. FORCE_COLOR set to any non-empty value enables color, except "0" or "false", which disable it.
+//  3. TERM set to "dumb" disables color.
+//  4. Otherwise color is enabled only if out is a terminal.
+func colorEnabled(out io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	switch force := os.Getenv("FORCE_COLOR"); force {
+	case "":
+	case "0", "false":
+		return false
+	default:
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(out)
+}
+
+// isTerminal reports whether out is a file that refers to a terminal.
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// SetColor controls whether the level in the header is colored with ANSI escape codes. It is off by
+// default; see SetColorAuto to decide based on the output and environment instead. It has no effect
+// unless the Logger uses a TextFormatter.
+func (l *Logger) SetColor(color bool) *Logger {
+	return l.withTextFormatter(func(f *TextFormatter) {
+		f.Color = color
+	})
+}
+
+// SetColorAuto colors the level in the header if the Logger's current output is a terminal, unless the
+// NO_COLOR, FORCE_COLOR, or TERM environment variables say otherwise, so the same binary writes color
+// locally and plain text in CI. Call it after the output is set. The signals are checked in this order:
+//
+//  1. NO_COLOR set to any non-empty value disables color.
+//  2. FORCE_COLOR set to any non-empty value enables color, except "0" or "false", which disable it.
+//  3. TERM set to "dumb" disables color.
+//  4. Otherwise color is enabled only if the output is a terminal.
+//
+// It has no effect unless the Logger uses a TextFormatter.
+func (l *Logger) SetColorAuto() *Logger {
+	return l.SetColor(colorEnabled(l.GetOutput()))
+}
+
+// DefaultLevelIcons returns the icons used by SetLevelIcons.
+func DefaultLevelIcons() map[Level]string {
+	return map[Level]string{
+		DebugLvl: "🐛",
+		InfoLvl:  "ℹ️",
+		WarnLvl:  "⚠️",
+		ErrorLvl: "❌",
+	}
+}
+
+// SetLevelIcons controls whether each line starts with an icon for its level, from DefaultLevelIcons, to
+// make local development logs easier to scan. It is off by default, and is ignored unless the Logger's
+// current output is a terminal, so files and piped output stay plain. Call it after the output is set. It
+// has no effect unless the Logger uses a TextFormatter.
+func (l *Logger) SetLevelIcons(icons bool) *Logger {
+	if !icons {
+		return l.SetLevelIconSet(nil)
+	}
+	return l.SetLevelIconSet(DefaultLevelIcons())
+}
+
+// SetLevelIconSet is like SetLevelIcons, but uses the given icons instead of the defaults. Levels missing
+// from icons get none, and a nil map turns icons off.
+func (l *Logger) SetLevelIconSet(icons map[Level]string) *Logger {
+	if icons != nil && !isTerminal(l.GetOutput()) {
+		return l
+	}
+	return l.withTextFormatter(func(f *TextFormatter) {
+		f.Icons = icons
+	})
+}
+
+// NewAuto creates a new Logger that writes to stdout at level, in a format chosen for where stdout goes:
+// human-readable text when it is a terminal, colored as SetColorAuto describes, and JSON otherwise, like
+// in a production container. Setting the LOG_FORMAT environment variable to "json" or "console" forces
+// one format regardless of stdout.
+func NewAuto(level Level) *Logger {
+	return newAuto(level, os.Stdout)
+}
+
+// newAuto is NewAuto, writing to out instead of stdout.
+func newAuto(level Level, out io.Writer) *Logger {
+	var console bool
+	switch strings.ToLower(os.Getenv(LogFormatEnv)) {
+	case "json":
+		console = false
+	case "console":
+		console = true
+	default:
+		console = isTerminal(out)
+	}
+	var formatter Formatter = JSONFormatter{}
+	if console {
+		formatter = TextFormatter{Color: colorEnabled(out)}
+	}
+	l, _ := NewWithOptions(WithLevel(level), WithOutput(out), WithFormatter(formatter))
+	return l
+}
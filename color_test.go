@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestColorEnabled(t *testing.T) {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		tty = nil
+	} else {
+		defer tty.Close()
+	}
+	type env struct {
+		noColor, forceColor, term string
+	}
+	colorTests := []struct {
+		env      env
+		tty      bool
+		expected bool
+	}{
+		{env{"", "", "xterm"}, false, false},
+		{env{"", "", "xterm"}, true, true},
+		{env{"", "", "dumb"}, true, false},
+		{env{"1", "", "xterm"}, true, false},
+		{env{"1", "1", "xterm"}, false, false},
+		{env{"", "1", "dumb"}, false, true},
+		{env{"", "0", "xterm"}, true, false},
+		{env{"", "false", "xterm"}, true, false},
+	}
+	for _, test := range colorTests {
+		if test.tty && tty == nil {
+			continue
+		}
+		t.Setenv("NO_COLOR", test.env.noColor)
+		t.Setenv("FORCE_COLOR", test.env.forceColor)
+		t.Setenv("TERM", test.env.term)
+		var out io.Writer = &bytes.Buffer{}
+		if test.tty {
+			out = tty
+		}
+		if got := colorEnabled(out); got != test.expected {
+			t.Errorf("Expected colorEnabled to be %t for %+v on a tty=%t, got %t instead\n", test.expected, test.env, test.tty, got)
+		}
+	}
+}
+
+func TestSetColorAuto(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetColorAuto().Warn("colored")
+	if !bytes.Contains(buf.Bytes(), []byte("\x1b[33m[WARN]\x1b[0m ")) {
+		t.Errorf("Expected a colored level, got %q instead\n", buf.String())
+	}
+
+	t.Setenv("FORCE_COLOR", "")
+	buf.Reset()
+	log.SetColorAuto().Warn("plain")
+	if !bytes.Contains(buf.Bytes(), []byte(" [WARN] ")) {
+		t.Errorf("Expected a plain level, got %q instead\n", buf.String())
+	}
+}
+
+func TestSetLevelIcons(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetLevelIcons(true).Error("not a terminal")
+	if strings.HasPrefix(buf.String(), "❌") {
+		t.Errorf("Expected no icon when not writing to a terminal, got %q instead\n", buf.String())
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("No terminal available:", err)
+	}
+	defer tty.Close()
+	log.SetOutput(tty).SetLevelIconSet(map[Level]string{ErrorLvl: "!!"})
+	if f := log.formatter.(TextFormatter); f.Icons[ErrorLvl] != "!!" {
+		t.Errorf("Expected the custom icon set to be used, got %v instead\n", f.Icons)
+	}
+	log.SetLevelIcons(false)
+	if f := log.formatter.(TextFormatter); f.Icons != nil {
+		t.Errorf("Expected icons to be turned off, got %v instead\n", f.Icons)
+	}
+}
+
+func TestNewAuto(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	autoTests := map[string]Formatter{
+		"":        JSONFormatter{},
+		"json":    JSONFormatter{},
+		"console": TextFormatter{},
+		"CONSOLE": TextFormatter{},
+		"bogus":   JSONFormatter{},
+	}
+	for env, expected := range autoTests {
+		t.Setenv(LogFormatEnv, env)
+		var buf bytes.Buffer
+		log := newAuto(WarnLvl, &buf)
+		if !reflect.DeepEqual(log.formatter, expected) {
+			t.Errorf("Expected %s=%q to choose %T, got %T instead\n", LogFormatEnv, env, expected, log.formatter)
+		}
+		if log.GetLevel() != WarnLvl || log.GetOutput() != &buf {
+			t.Errorf("Expected a WARN Logger writing to the buffer, got %s writing to %v instead\n", log.GetLevel(),
+				log.GetOutput())
+		}
+	}
+	if NewAuto(InfoLvl).GetOutput() != os.Stdout {
+		t.Error("Expected NewAuto to write to stdout")
+	}
+}
@@ -0,0 +1,94 @@
+//go:build log_debug || !log_nodebug
+// +build log_debug !log_nodebug
+
+package logging
+
+// The Debug methods are built unless the log_nodebug build tag is set, in which case the stubs in
+// debug_nodebug.go replace them. See debug_nodebug.go for details.
+
+// Debug writes a log entry with the Level of DebugLvl, joining each argument passed
+// with a space, as fmt.Sprintln does. See SetSeparator.
+func (l *Logger) Debug(msg ...interface{}) {
+	if !l.enabled(DebugLvl) {
+		return
+	}
+	l.log(DebugLvl, msg...)
+}
+
+// Debugf writes a log entry with the Level of DebugLvl, interpolating the format
+// string with the arguments passed. See fmt.Sprintf for information on variable
+// placeholders in the format string.
+func (l *Logger) Debugf(format string, msg ...interface{}) {
+	if !l.enabled(DebugLvl) {
+		return
+	}
+	l.logf(format, DebugLvl, msg...)
+}
+
+// Debugw writes a log entry with the Level of DebugLvl, with msg as the message and fields attached.
+func (l *Logger) Debugw(msg string, fields ...Field) {
+	if !l.enabled(DebugLvl) {
+		return
+	}
+	l.logw(DebugLvl, msg, fields)
+}
+
+// Debugwf writes a log entry with the Level of DebugLvl, interpolating the format string with the
+// arguments passed, with fields attached.
+func (l *Logger) Debugwf(fields Fields, format string, args ...interface{}) {
+	if !l.enabled(DebugLvl) {
+		return
+	}
+	l.logwf(fields, format, DebugLvl, args...)
+}
+
+// DebugDepth is like Debug, but skips an extra skip stack frames when finding the caller.
+func (l *Logger) DebugDepth(skip int, msg ...interface{}) {
+	if !l.enabled(DebugLvl) {
+		return
+	}
+	l = l.withCallDepth(skip)
+	l.log(DebugLvl, msg...)
+}
+
+// DebugfDepth is like Debugf, but skips an extra skip stack frames when finding the caller.
+func (l *Logger) DebugfDepth(skip int, format string, msg ...interface{}) {
+	if !l.enabled(DebugLvl) {
+		return
+	}
+	l = l.withCallDepth(skip)
+	l.logf(format, DebugLvl, msg...)
+}
+
+// DebugBytes writes a log entry with the Level of DebugLvl, using b as the message.
+func (l *Logger) DebugBytes(b []byte) {
+	if !l.enabled(DebugLvl) {
+		return
+	}
+	l.logBytes(DebugLvl, b)
+}
+
+// Debugln writes a log entry with the Level of DebugLvl, joining each argument passed as fmt.Sprintln
+// does.
+func (l *Logger) Debugln(msg ...interface{}) {
+	if !l.enabled(DebugLvl) {
+		return
+	}
+	l.logln(DebugLvl, msg...)
+}
+
+// TryDebugf is like Debugf, but returns any error writing the entry.
+func (l *Logger) TryDebugf(format string, msg ...interface{}) error {
+	if !l.enabled(DebugLvl) {
+		return nil
+	}
+	return l.tryLogf(format, DebugLvl, msg...)
+}
+
+// TryDebug is like Debug, but returns any error writing the entry.
+func (l *Logger) TryDebug(msg ...interface{}) error {
+	if !l.enabled(DebugLvl) {
+		return nil
+	}
+	return l.tryLog(DebugLvl, msg...)
+}
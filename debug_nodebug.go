@@ -0,0 +1,50 @@
+//go:build log_nodebug && !log_debug
+// +build log_nodebug,!log_debug
+
+package logging
+
+// Building with the log_nodebug tag, like
+//
+//	go build -tags log_nodebug
+//
+// replaces the Debug methods with empty stubs, so they write nothing regardless of the Logger's Level,
+// and cost no more than a call to an empty function, which the compiler usually inlines away. This is
+// for latency-critical release builds that must guarantee debug logging is free. The tradeoff is that
+// debug output can't be turned back on at run time, by SetLevel or otherwise; that takes a rebuild
+// without the tag. The arguments passed are still evaluated at the call site, so expensive ones should
+// still be guarded, with GetLevel for instance. The log_debug tag overrides log_nodebug, and keeps the
+// full implementation.
+//
+// Only the Debug methods, including TryDebug and TryDebugf, which always return nil, are stubbed out.
+// Entries written at DebugLvl through other means, like an adapter for another logging package, are
+// still filtered by Level as usual.
+
+// Debug does nothing in builds with the log_nodebug tag.
+func (l *Logger) Debug(msg ...interface{}) {}
+
+// Debugf does nothing in builds with the log_nodebug tag.
+func (l *Logger) Debugf(format string, msg ...interface{}) {}
+
+// Debugw does nothing in builds with the log_nodebug tag.
+func (l *Logger) Debugw(msg string, fields ...Field) {}
+
+// Debugwf does nothing in builds with the log_nodebug tag.
+func (l *Logger) Debugwf(fields Fields, format string, args ...interface{}) {}
+
+// DebugDepth does nothing in builds with the log_nodebug tag.
+func (l *Logger) DebugDepth(skip int, msg ...interface{}) {}
+
+// DebugfDepth does nothing in builds with the log_nodebug tag.
+func (l *Logger) DebugfDepth(skip int, format string, msg ...interface{}) {}
+
+// DebugBytes does nothing in builds with the log_nodebug tag.
+func (l *Logger) DebugBytes(b []byte) {}
+
+// Debugln does nothing in builds with the log_nodebug tag.
+func (l *Logger) Debugln(msg ...interface{}) {}
+
+// TryDebugf does nothing, and returns nil, in builds with the log_nodebug tag.
+func (l *Logger) TryDebugf(format string, msg ...interface{}) error { return nil }
+
+// TryDebug does nothing, and returns nil, in builds with the log_nodebug tag.
+func (l *Logger) TryDebug(msg ...interface{}) error { return nil }
@@ -0,0 +1,39 @@
+//go:build log_nodebug && !log_debug
+// +build log_nodebug,!log_debug
+
+package logging
+
+import (
+	"bytes"
+	"testing"
+)
+
+// debugEnabled reports whether the Debug methods write anything in this build. See debug_nodebug.go.
+const debugEnabled = false
+
+func TestNoDebug(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Debug("debug")
+	log.Debugf("debug %d", 1)
+	log.Debugw("debug", Int("n", 1))
+	log.Debugwf(Fields{"n": 1}, "debug %d", 1)
+	log.DebugDepth(1, "debug")
+	log.DebugfDepth(1, "debug %d", 1)
+	log.DebugBytes([]byte("debug"))
+	log.Debugln("debug", 1)
+	if err := log.TryDebug("debug"); err != nil {
+		t.Errorf("Expected no error, got %+v instead\n", err)
+	}
+	log.TryDebugf("debug %d", 1)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no debug output, got '%s' instead\n", buf.String())
+	}
+	log.Info("info")
+	if buf.Len() == 0 {
+		t.Error("Expected info output")
+	}
+}
@@ -0,0 +1,137 @@
+//go:build log_debug || !log_nodebug
+// +build log_debug !log_nodebug
+
+package logging
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// debugEnabled reports whether the Debug methods write anything in this build. See debug_nodebug.go.
+const debugEnabled = true
+
+func TestAddHook(t *testing.T) {
+	log, err := New(DebugLvl, ioutil.Discard, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	parent, child := levelCounter{}, levelCounter{}
+	log.AddHook(parent)
+	c := log.Child(Fields{"k": "v"}).AddHook(child)
+	log.Debug("one")
+	log.Warn("two")
+	c.Error("three")
+	if parent[DebugLvl] != 1 || parent[WarnLvl] != 1 || parent[ErrorLvl] != 1 {
+		t.Errorf("Expected the parent hook to see every entry, got %v instead\n", parent)
+	}
+	if len(child) != 1 || child[ErrorLvl] != 1 {
+		t.Errorf("Expected the child hook to see only the child's entry, got %v instead\n", child)
+	}
+}
+
+func TestSetMaxMessageLength(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetMaxMessageLength(4).Child(Fields{"size": 2048}).Debug(strings.Repeat("x", 2048))
+	expected := ": xxxx…(truncated, 2044 bytes omitted) size=2048\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+}
+
+func TestSetPackageLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithFormatter(upperFormatter{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	_, file, _, _ := runtime.Caller(0)
+	pkg := strings.TrimPrefix(filepath.Dir(file), "/")
+	log.SetPackageLevel(pkg, DebugLvl)
+	log.Debug("included")
+	if buf.String() != "DEBUG: included\n" {
+		t.Errorf("Expected the package level to include DEBUG, got '%s' instead\n", buf.String())
+	}
+	if log.GetLevel() != InfoLvl {
+		t.Errorf("Expected the Logger's Level to be unchanged, got %s instead\n", log.GetLevel())
+	}
+
+	buf.Reset()
+	log.SetPackageLevel(pkg, ErrorLvl)
+	log.Warn("excluded")
+	log.SetPackageLevel("github.com/DramaFever/other", DebugLvl)
+	log.Debug("excluded")
+	if buf.String() != "" {
+		t.Errorf("Expected the package level to exclude WARN and DEBUG, got '%s' instead\n", buf.String())
+	}
+	if len(log.packageLevels) != 2 {
+		t.Errorf("Expected setting a package again to replace it, got %v instead\n", log.packageLevels)
+	}
+}
+
+func TestSetSampleRate(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC)
+	log, err := NewWithOptions(WithLevel(DebugLvl), WithOutput(&buf), WithFormatter(upperFormatter{}),
+		WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log.SetSampleRate(DebugLvl, 3)
+	for i := 0; i < 7; i++ {
+		log.Debug("hot " + strconv.Itoa(i))
+		log.Info("cold")
+	}
+	expected := "DEBUG: hot 0\n" + strings.Repeat("INFO: cold\n", 3) +
+		"DEBUG: sampled out 2 DEBUG messages\nDEBUG: hot 3\n" + strings.Repeat("INFO: cold\n", 3) +
+		"DEBUG: hot 6\nINFO: cold\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output to be '%s', got '%s' instead\n", expected, buf.String())
+	}
+
+	buf.Reset()
+	now = now.Add(sampleReportInterval)
+	for i := 7; i < 10; i++ {
+		log.Debug("hot " + strconv.Itoa(i))
+	}
+	expected = "DEBUG: sampled out 4 DEBUG messages\nDEBUG: hot 9\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output to be '%s', got '%s' instead\n", expected, buf.String())
+	}
+}
+
+func TestClone(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", map[string]string{})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.AddTags(map[string]string{"subsystem": "base"})
+	clone := log.Clone().SetLevel(DebugLvl).AddTags(map[string]string{"subsystem": "clone"})
+	if log.GetLevel() != InfoLvl {
+		t.Errorf("Expected original level to be %s, got %s instead\n", InfoLvl, log.GetLevel())
+	}
+	if clone.GetLevel() != DebugLvl {
+		t.Errorf("Expected clone level to be %s, got %s instead\n", DebugLvl, clone.GetLevel())
+	}
+	if clone.flock == log.flock {
+		t.Error("Expected clone to have its own lock")
+	}
+	if log.tags["subsystem"] != "base" {
+		t.Errorf("Expected original tag to be %s, got %s instead\n", "base", log.tags["subsystem"])
+	}
+	clone.Debug("from the clone")
+	if !strings.Contains(buf.String(), "from the clone") {
+		t.Errorf("Expected clone to write to the shared output, got '%s'\n", buf.String())
+	}
+}
@@ -0,0 +1,71 @@
+package logging
+
+// The Depth methods behave like their counterparts without the Depth suffix, but add skip to the
+// Logger's call depth for that one entry. They are meant for helpers that are called from varying stack
+// depths, where SetCallDepth can't express the right offset. A skip of 1 attributes the entry to the
+// caller of the function calling the Depth method.
+
+// InfofDepth is like Infof, but skips an extra skip stack frames when finding the caller.
+func (l *Logger) InfofDepth(skip int, format string, msg ...interface{}) {
+	if !l.enabled(InfoLvl) {
+		return
+	}
+	l = l.withCallDepth(skip)
+	l.logf(format, InfoLvl, msg...)
+}
+
+// InfoDepth is like Info, but skips an extra skip stack frames when finding the caller.
+func (l *Logger) InfoDepth(skip int, msg ...interface{}) {
+	if !l.enabled(InfoLvl) {
+		return
+	}
+	l = l.withCallDepth(skip)
+	l.log(InfoLvl, msg...)
+}
+
+// WarnfDepth is like Warnf, but skips an extra skip stack frames when finding the caller.
+func (l *Logger) WarnfDepth(skip int, format string, msg ...interface{}) {
+	if !l.enabled(WarnLvl) {
+		return
+	}
+	l = l.withCallDepth(skip)
+	l.logf(format, WarnLvl, msg...)
+	l.toSentry(format, msg, WarnLvl, nil)
+}
+
+// WarnDepth is like Warn, but skips an extra skip stack frames when finding the caller.
+func (l *Logger) WarnDepth(skip int, msg ...interface{}) {
+	if !l.enabled(WarnLvl) {
+		return
+	}
+	l = l.withCallDepth(skip)
+	l.log(WarnLvl, msg...)
+	l.toSentry(l.joinln(msg...), []interface{}{}, WarnLvl, nil)
+}
+
+// ErrorfDepth is like Errorf, but skips an extra skip stack frames when finding the caller.
+func (l *Logger) ErrorfDepth(skip int, format string, msg ...interface{}) {
+	if !l.enabled(ErrorLvl) {
+		return
+	}
+	l = l.withCallDepth(skip)
+	l.logf(format, ErrorLvl, msg...)
+	l.toSentry(format, msg, ErrorLvl, nil)
+}
+
+// ErrorDepth is like Error, but skips an extra skip stack frames when finding the caller.
+func (l *Logger) ErrorDepth(skip int, msg ...interface{}) {
+	if !l.enabled(ErrorLvl) {
+		return
+	}
+	l = l.withCallDepth(skip)
+	l.log(ErrorLvl, msg...)
+	l.toSentry(l.joinln(msg...), []interface{}{}, ErrorLvl, nil)
+}
+
+// withCallDepth returns a copy of the Logger whose call depth is increased by skip.
+func (l *Logger) withCallDepth(skip int) *Logger {
+	newLogger := l.shallowCopy()
+	newLogger.calldepth += skip
+	return newLogger
+}
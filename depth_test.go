@@ -0,0 +1,42 @@
+//go:build log_debug || !log_nodebug
+// +build log_debug !log_nodebug
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// logFromHelper logs through a helper, attributing the entry to its caller.
+func logFromHelper(l *Logger, msg string) {
+	l.InfofDepth(1, "%s", msg)
+}
+
+func TestDepth(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	_, file, line, _ := runtime.Caller(0)
+	logFromHelper(log, "Test output")
+	expected := fmt.Sprintf("%s:%d: Test output\n", file, line+1)
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+
+	buf.Reset()
+	log.DebugDepth(0, "Test output")
+	_, _, line, _ = runtime.Caller(0)
+	expected = fmt.Sprintf("%s:%d: Test output\n", file, line-1)
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+	if log.calldepth != 0 {
+		t.Errorf("Expected call depth to be unchanged, got %d\n", log.calldepth)
+	}
+}
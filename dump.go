@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// DefaultDumpLimit is the number of bytes Dump writes, unless SetDumpLimit sets another limit.
+const DefaultDumpLimit = 4096
+
+// Dump writes a log entry with the given Level holding a hex and ASCII dump of data, in the format of
+// "hexdump -C", under label:
+//
+//	2015-07-02T13:28:42 [DEBUG] /my/test/file.go:145: handshake (20 bytes):
+//	00000000  16 03 01 00 0f 01 00 00  0b 03 03 68 65 6c 6c 6f  |...........hello|
+//	00000010  21 0a 00 00                                       |!...|
+//
+// The dump is only built if the Logger's Level includes level. Each line of the dump is written after the
+// Logger's continuation prefix, if it has one. Only the first DefaultDumpLimit bytes of data are dumped,
+// followed by a count of the bytes left out; see SetDumpLimit.
+//
+// Like the other log methods, dumps at WarnLvl and ErrorLvl are sent to Sentry, if Sentry has been
+// configured.
+func (l *Logger) Dump(level Level, label string, data []byte) {
+	if !l.enabled(level) {
+		return
+	}
+	msg := l.dump(label, data)
+	l.log(level, msg)
+	if level == WarnLvl || level == ErrorLvl {
+		l.toSentry("%s", []interface{}{msg}, level, nil)
+	}
+}
+
+// SetDumpLimit sets the number of bytes of data Dump writes. Larger buffers are truncated, so dumping a
+// whole payload can't flood the output. Pass 0 to dump everything.
+func (l *Logger) SetDumpLimit(limit int) *Logger {
+	if limit <= 0 {
+		limit = -1
+	}
+	l.dumpLimit = limit
+	return l
+}
+
+// dump returns the message Dump writes for data.
+func (l *Logger) dump(label string, data []byte) string {
+	limit := l.dumpLimit
+	if limit == 0 {
+		limit = DefaultDumpLimit
+	}
+	var b strings.Builder
+	b.WriteString(label)
+	b.WriteString(" (")
+	b.WriteString(strconv.Itoa(len(data)))
+	b.WriteString(" bytes):\n")
+	if limit > 0 && len(data) > limit {
+		b.WriteString(hex.Dump(data[:limit]))
+		b.WriteString("... ")
+		b.WriteString(strconv.Itoa(len(data) - limit))
+		b.WriteString(" more bytes")
+		return b.String()
+	}
+	b.WriteString(hex.Dump(data))
+	return strings.TrimSuffix(b.String(), "\n")
+}
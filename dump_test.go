@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDump(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	data := []byte("\x16\x03\x01\x00\x0f\x01\x00\x00\x0b\x03\x03hello!\n\x00\x00")
+
+	log.Dump(DebugLvl, "handshake", data)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output below the Logger's Level, got '%s' instead\n", buf.String())
+	}
+
+	log.SetContinuationPrefix("\t").Dump(InfoLvl, "handshake", data)
+	expected := ": handshake (20 bytes):\n" +
+		"\t00000000  16 03 01 00 0f 01 00 00  0b 03 03 68 65 6c 6c 6f  |...........hello|\n" +
+		"\t00000010  21 0a 00 00                                       |!...|\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+}
+
+func TestSetDumpLimit(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	data := bytes.Repeat([]byte("a"), DefaultDumpLimit+10)
+
+	log.Dump(DebugLvl, "payload", data)
+	if !strings.HasSuffix(buf.String(), "|aaaaaaaaaaaaaaaa|\n... 10 more bytes\n") {
+		t.Errorf("Expected the dump to be truncated, got '%s' instead\n", buf.String()[buf.Len()-100:])
+	}
+
+	buf.Reset()
+	log.SetDumpLimit(20).Dump(DebugLvl, "payload", data)
+	if lines := strings.Count(buf.String(), "\n"); lines != 4 {
+		t.Errorf("Expected the header, 2 lines of dump, and the count, got %d lines instead\n", lines)
+	}
+	if !strings.HasSuffix(buf.String(), "... 4086 more bytes\n") {
+		t.Errorf("Expected the dump to be truncated at 20 bytes, got '%s' instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log.SetDumpLimit(0).Dump(DebugLvl, "payload", data)
+	if strings.Contains(buf.String(), "more bytes") {
+		t.Error("Expected a limit of 0 to dump everything")
+	}
+}
+
+func TestDumpSentry(t *testing.T) {
+	log, transport := newSentryTestLogger(t)
+	log.Dump(ErrorLvl, "percent", []byte("%d%%"))
+	log.FlushSentry(time.Second)
+	if len(transport.packets) != 1 || !strings.Contains(transport.packets[0].Message, "|%d%%|") {
+		t.Errorf("Expected the dump to be sent as is, got %+v instead\n", transport.packets)
+	}
+}
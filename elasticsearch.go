@@ -0,0 +1,167 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultElasticsearchIndex is the index an ElasticsearchConfig writes to when none is given, one per
+	// day.
+	DefaultElasticsearchIndex = "logs-{2006.01.02}"
+
+	defaultElasticsearchMaxBatchSize = 5 << 20
+)
+
+// ElasticsearchConfig controls where an Elasticsearch writer indexes log entries, and how it batches
+// them. The zero value is usable, and gives the defaults described on each field.
+type ElasticsearchConfig struct {
+	// Client is used to make requests. It defaults to a client with a ten second timeout, like a
+	// WebhookWriter's.
+	Client *http.Client
+	// Index is the name of the index each entry is written to. Any Go time layout in braces is replaced
+	// with the current UTC date in that layout, so "logs-{2006.01.02}" writes to an index per day, like
+	// logs-2024.06.01. It defaults to DefaultElasticsearchIndex.
+	Index string
+	// MaxBatchSize is the number of bytes a bulk request can hold before it is sent. It defaults to 5MB.
+	MaxBatchSize int
+	// MaxBatchAge is the longest an entry waits before it is sent. It defaults to five seconds.
+	MaxBatchAge time.Duration
+	// MaxRetries is the number of times a failed bulk request is retried, with exponential backoff. It
+	// defaults to 3; set it to -1 to never retry.
+	MaxRetries int
+	// ErrorHandler, if set, is called when Elasticsearch rejects some of the entries in a bulk request
+	// it otherwise accepted, like ones that don't match the index's mapping.
+	ErrorHandler func(error)
+	// Fallback, if set, is written each batch of entries that could not be sent, like os.Stderr.
+	Fallback io.Writer
+}
+
+// NewElasticsearchWriter returns a writer that indexes log entries into the Elasticsearch cluster at
+// url, like "http://localhost:9200", using the _bulk API. Each Write must be a single JSON document, as
+// written by a JSONFormatter. Entries are batched and sent like a WebhookWriter's, and Write blocks
+// while the cluster falls behind, rather than holding an unbounded backlog. Close must be called to send
+// the final batch.
+func NewElasticsearchWriter(url string, config ElasticsearchConfig) *WebhookWriter {
+	if config.Index == "" {
+		config.Index = DefaultElasticsearchIndex
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = defaultElasticsearchMaxBatchSize
+	}
+	return NewWebhookWriter(strings.TrimRight(url, "/")+"/_bulk", WebhookConfig{
+		Client:      config.Client,
+		ContentType: "application/x-ndjson",
+		Encode: func(batch []byte) []byte {
+			return elasticsearchBulk(batch, elasticsearchIndex(config.Index, time.Now().UTC()))
+		},
+		MaxBatchSize: config.MaxBatchSize,
+		MaxBatchAge:  config.MaxBatchAge,
+		MaxRetries:   config.MaxRetries,
+		Fallback:     config.Fallback,
+		OnResponse: func(body []byte) {
+			if err := elasticsearchBulkError(body); err != nil && config.ErrorHandler != nil {
+				config.ErrorHandler(err)
+			}
+		},
+	})
+}
+
+// LogToElasticsearch creates a new Logger that indexes its entries into the Elasticsearch cluster at url,
+// as configured by config. Entries are written by a JSONFormatter, with their time as @timestamp, the
+// field Kibana expects. Rejected entries are reported to the Logger's error handler, unless config has
+// its own. The connection is closed by the Logger's Close method, once the final batch is sent.
+//
+// If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
+// are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
+// the version of your software running, etc.
+func LogToElasticsearch(level Level, url string, config ElasticsearchConfig, sentry string, sentryTags map[string]string) (*Logger, error) {
+	var l *Logger
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(err error) {
+			l.handleError(err)
+		}
+	}
+	l, err := NewWithOptions(WithLevel(level), WithOutput(NewElasticsearchWriter(url, config)),
+		WithFormatter(JSONFormatter{KeyMap: JSONKeyMap{"time": "@timestamp"}}), WithSentry(sentry, sentryTags))
+	return l, err
+}
+
+// elasticsearchIndex returns the index name for pattern at t, replacing each time layout in braces.
+func elasticsearchIndex(pattern string, t time.Time) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		end := strings.IndexByte(pattern, '}')
+		if start < 0 || end < start {
+			b.WriteString(pattern)
+			return b.String()
+		}
+		b.WriteString(pattern[:start])
+		b.WriteString(t.Format(pattern[start+1 : end]))
+		pattern = pattern[end+1:]
+	}
+}
+
+// elasticsearchBulk returns the body of a bulk request that indexes each line in batch into index.
+func elasticsearchBulk(batch []byte, index string) []byte {
+	var action []byte
+	action = append(action, `{"index":{"_index":`...)
+	appendJSONString(&action, index)
+	action = append(action, "}}\n"...)
+
+	body := make([]byte, 0, len(batch)+len(action)*bytes.Count(batch, []byte("\n")))
+	for _, line := range bytes.Split(batch, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		body = append(body, action...)
+		body = append(body, line...)
+		body = append(body, '\n')
+	}
+	return body
+}
+
+// elasticsearchBulkResponse is the part of a bulk response that reports which documents failed.
+type elasticsearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// elasticsearchBulkError returns an error describing the documents a bulk response says failed, or nil
+// if they all succeeded.
+func elasticsearchBulkError(body []byte) error {
+	var resp elasticsearchBulkResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("elasticsearch: can't read bulk response: %v", err)
+	}
+	if !resp.Errors {
+		return nil
+	}
+	failed := 0
+	var first string
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Status >= 200 && result.Status <= 299 {
+				continue
+			}
+			failed++
+			if first == "" {
+				first = result.Error.Type + ": " + result.Error.Reason
+			}
+		}
+	}
+	return fmt.Errorf("elasticsearch: %d of %d entries were not indexed, the first with %s", failed,
+		len(resp.Items), first)
+}
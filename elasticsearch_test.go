@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestElasticsearchIndex(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 13, 28, 42, 0, time.UTC)
+	indexTests := map[string]string{
+		"logs-{2006.01.02}":       "logs-2024.06.01",
+		"app-2-{2006}-{01}":       "app-2-2024-06",
+		"static":                  "static",
+		"unclosed-{2006.01.02":    "unclosed-{2006.01.02",
+		"backwards-}2006{.01.02}": "backwards-}2006{.01.02}",
+	}
+	for pattern, expected := range indexTests {
+		if got := elasticsearchIndex(pattern, now); got != expected {
+			t.Errorf("Expected %q to become %q, got %q instead\n", pattern, expected, got)
+		}
+	}
+}
+
+func TestElasticsearchBulk(t *testing.T) {
+	body := elasticsearchBulk([]byte("{\"msg\":\"one\"}\n\n{\"msg\":\"two\"}\n"), "logs-2024.06.01")
+	expected := "{\"index\":{\"_index\":\"logs-2024.06.01\"}}\n{\"msg\":\"one\"}\n" +
+		"{\"index\":{\"_index\":\"logs-2024.06.01\"}}\n{\"msg\":\"two\"}\n"
+	if string(body) != expected {
+		t.Errorf("Expected %q, got %q instead\n", expected, body)
+	}
+}
+
+func TestElasticsearchBulkError(t *testing.T) {
+	if err := elasticsearchBulkError([]byte(`{"errors":false,"items":[{"index":{"status":201}}]}`)); err != nil {
+		t.Errorf("Expected no error, got %v instead\n", err)
+	}
+	err := elasticsearchBulkError([]byte(`{"errors":true,"items":[{"index":{"status":201}},` +
+		`{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}},` +
+		`{"index":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"queue full"}}}]}`))
+	expected := "elasticsearch: 2 of 3 entries were not indexed, the first with mapper_parsing_exception: failed to parse"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v instead\n", expected, err)
+	}
+	if err := elasticsearchBulkError([]byte("not json")); err == nil {
+		t.Error("Expected an error for an unreadable response")
+	}
+}
+
+func TestLogToElasticsearch(t *testing.T) {
+	var mu sync.Mutex
+	var paths, types, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		types = append(types, r.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		w.Write([]byte(`{"errors":true,"items":[{"index":{"status":201}},` +
+			`{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad"}}}]}`))
+	}))
+	defer server.Close()
+
+	var handled []error
+	log, err := LogToElasticsearch(DebugLvl, server.URL+"/", ElasticsearchConfig{Index: "test-{2006}"}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetErrorHandler(func(err error) {
+		handled = append(handled, err)
+	})
+	log.Info("first")
+	log.Info("second")
+	if err := log.Close(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if len(bodies) != 1 || paths[0] != "/_bulk" || types[0] != "application/x-ndjson" {
+		t.Fatalf("Expected a single bulk request, got %q to %q instead\n", bodies, paths)
+	}
+	lines := strings.Split(strings.TrimSuffix(bodies[0], "\n"), "\n")
+	index := `{"index":{"_index":"test-` + time.Now().UTC().Format("2006") + `"}}`
+	if len(lines) != 4 || lines[0] != index || lines[2] != index ||
+		!strings.HasPrefix(lines[1], `{"@timestamp":"`) || !strings.HasSuffix(lines[3], `"msg":"second"}`) {
+		t.Errorf("Expected two indexed documents, got %q instead\n", lines)
+	}
+	if len(handled) != 1 || !strings.Contains(handled[0].Error(), "1 of 2 entries were not indexed") {
+		t.Errorf("Expected the rejected entry to be reported, got %v instead\n", handled)
+	}
+}
+
+func TestElasticsearchWriterErrorHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":true,"items":[{"index":{"status":400,"error":{"type":"x","reason":"y"}}}]}`))
+	}))
+	defer server.Close()
+	var handled error
+	w := NewElasticsearchWriter(server.URL, ElasticsearchConfig{ErrorHandler: func(err error) {
+		handled = err
+	}})
+	w.Write([]byte("{}\n"))
+	w.Close()
+	if handled == nil {
+		t.Errorf("Expected the error handler to be called, got %v instead\n", handled)
+	}
+}
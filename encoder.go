@@ -0,0 +1,211 @@
+package logging
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encoder appends field keys and values to a buffer in a particular format, like JSON or key=value text.
+// Formatters write Fields with EncodeFields, which walks them in sorted order and calls the Encoder for
+// each key and value by type, so every format shares the same field handling and each only has to know
+// how to write, and escape, a single key or value.
+//
+// The Encoders used by the built-in Formatters are exported, so a custom Formatter can reuse them.
+type Encoder interface {
+	// AppendKey appends a field's key, along with whatever separates it from the field before it and
+	// from its value.
+	AppendKey(buf *[]byte, key string)
+	// AppendGroup appends a group of fields, nested Fields made by WithGroup, under key.
+	AppendGroup(buf *[]byte, key string, group Fields)
+
+	AppendString(buf *[]byte, s string)
+	AppendInt(buf *[]byte, i int64)
+	AppendUint(buf *[]byte, u uint64)
+	// AppendFloat appends f, which was a float32 if bitSize is 32, or a float64 if it is 64.
+	AppendFloat(buf *[]byte, f float64, bitSize int)
+	AppendBool(buf *[]byte, b bool)
+	AppendTime(buf *[]byte, t time.Time)
+	AppendDuration(buf *[]byte, d time.Duration)
+	AppendNil(buf *[]byte)
+}
+
+// EncodeFields appends fields to buf with enc, sorted by key.
+func EncodeFields(enc Encoder, buf *[]byte, fields Fields) {
+	for _, k := range fields.sortedKeys() {
+		if group, ok := fields[k].(Fields); ok {
+			enc.AppendGroup(buf, k, group)
+			continue
+		}
+		enc.AppendKey(buf, k)
+		EncodeValue(enc, buf, fields[k])
+	}
+}
+
+// EncodeValue appends v to buf with the Encoder method for its type. Errors are appended as their
+// messages, and values of other types as strings, formatted as by fmt.Sprint.
+func EncodeValue(enc Encoder, buf *[]byte, v interface{}) {
+	switch n := v.(type) {
+	case nil:
+		enc.AppendNil(buf)
+	case int:
+		enc.AppendInt(buf, int64(n))
+	case int8:
+		enc.AppendInt(buf, int64(n))
+	case int16:
+		enc.AppendInt(buf, int64(n))
+	case int32:
+		enc.AppendInt(buf, int64(n))
+	case int64:
+		enc.AppendInt(buf, n)
+	case uint:
+		enc.AppendUint(buf, uint64(n))
+	case uint8:
+		enc.AppendUint(buf, uint64(n))
+	case uint16:
+		enc.AppendUint(buf, uint64(n))
+	case uint32:
+		enc.AppendUint(buf, uint64(n))
+	case uint64:
+		enc.AppendUint(buf, n)
+	case float32:
+		enc.AppendFloat(buf, float64(n), 32)
+	case float64:
+		enc.AppendFloat(buf, n, 64)
+	case bool:
+		enc.AppendBool(buf, n)
+	case string:
+		enc.AppendString(buf, n)
+	case time.Time:
+		enc.AppendTime(buf, n)
+	case time.Duration:
+		enc.AppendDuration(buf, n)
+	case error:
+		enc.AppendString(buf, n.Error())
+	default:
+		enc.AppendString(buf, fmt.Sprint(v))
+	}
+}
+
+// typedEncoder is implemented by the Formatters that encode the typed fields of an Entry themselves, with
+// encodeEntryFields, so they don't have to be merged into its Fields.
+type typedEncoder interface {
+	encodesTyped()
+}
+
+func (TextFormatter) encodesTyped()        {}
+func (JSONFormatter) encodesTyped()        {}
+func (StackdriverFormatter) encodesTyped() {}
+
+// encodeEntryFields appends the Fields and typed fields of e to buf with enc, sorted by key, as
+// EncodeFields would if the typed fields were merged into the Fields. Typed fields win over Fields with
+// the same key, and later typed fields over earlier ones. The typed fields are few, so the next one in
+// order is found by scanning them, rather than by sorting a copy.
+func encodeEntryFields(enc Encoder, buf *[]byte, e *Entry) {
+	if len(e.typed) == 0 {
+		EncodeFields(enc, buf, e.Fields)
+		return
+	}
+	keys := e.Fields.sortedKeys()
+	i := 0
+	last, started := "", false
+	for {
+		next := -1
+		for j := range e.typed {
+			f := &e.typed[j]
+			if f.kind == skipField || started && f.Key <= last {
+				continue
+			}
+			if next < 0 || f.Key <= e.typed[next].Key {
+				next = j
+			}
+		}
+		for ; i < len(keys) && (next < 0 || keys[i] < e.typed[next].Key); i++ {
+			if group, ok := e.Fields[keys[i]].(Fields); ok {
+				enc.AppendGroup(buf, keys[i], group)
+				continue
+			}
+			enc.AppendKey(buf, keys[i])
+			EncodeValue(enc, buf, e.Fields[keys[i]])
+		}
+		if next < 0 {
+			return
+		}
+		f := e.typed[next]
+		if i < len(keys) && keys[i] == f.Key {
+			i++
+		}
+		enc.AppendKey(buf, f.Key)
+		encodeField(enc, buf, f)
+		last, started = f.Key, true
+	}
+}
+
+// encodeField appends the value of f to buf with the Encoder method for its kind, without boxing it.
+func encodeField(enc Encoder, buf *[]byte, f Field) {
+	switch f.kind {
+	case stringField:
+		enc.AppendString(buf, f.str)
+	case intField:
+		enc.AppendInt(buf, f.num)
+	case floatField:
+		enc.AppendFloat(buf, math.Float64frombits(uint64(f.num)), 64)
+	case boolField:
+		enc.AppendBool(buf, f.num == 1)
+	case durationField:
+		enc.AppendDuration(buf, time.Duration(f.num))
+	default:
+		EncodeValue(enc, buf, f.any)
+	}
+}
+
+// TextEncoder is the Encoder used by the TextFormatter. It writes each field as a space and a key=value
+// pair, quoting values that would be ambiguous unquoted, and writes the fields of groups under dotted
+// keys.
+type TextEncoder struct {
+	prefix string
+}
+
+// AppendKey appends a space, key, and an equals sign to buf.
+func (enc TextEncoder) AppendKey(buf *[]byte, key string) {
+	*buf = append(*buf, ' ')
+	*buf = append(*buf, enc.prefix...)
+	*buf = append(*buf, key...)
+	*buf = append(*buf, '=')
+}
+
+// AppendGroup appends the fields of group to buf, with their keys prefixed by key and a dot.
+func (enc TextEncoder) AppendGroup(buf *[]byte, key string, group Fields) {
+	EncodeFields(TextEncoder{prefix: enc.prefix + key + "."}, buf, group)
+}
+
+// AppendString appends s to buf, quoted if it is empty or holds spaces, equals signs, quotes, or
+// newlines.
+func (TextEncoder) AppendString(buf *[]byte, s string) {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		*buf = strconv.AppendQuote(*buf, s)
+		return
+	}
+	*buf = append(*buf, s...)
+}
+
+func (TextEncoder) AppendInt(buf *[]byte, i int64)   { *buf = strconv.AppendInt(*buf, i, 10) }
+func (TextEncoder) AppendUint(buf *[]byte, u uint64) { *buf = strconv.AppendUint(*buf, u, 10) }
+func (TextEncoder) AppendBool(buf *[]byte, b bool)   { *buf = strconv.AppendBool(*buf, b) }
+func (TextEncoder) AppendNil(buf *[]byte)            { *buf = append(*buf, "<nil>"...) }
+
+func (TextEncoder) AppendFloat(buf *[]byte, f float64, bitSize int) {
+	*buf = strconv.AppendFloat(*buf, f, 'g', -1, bitSize)
+}
+
+// AppendTime appends t to buf in RFC 3339 format.
+func (TextEncoder) AppendTime(buf *[]byte, t time.Time) {
+	*buf = t.AppendFormat(*buf, time.RFC3339Nano)
+}
+
+// AppendDuration appends d to buf like "1.5s".
+func (TextEncoder) AppendDuration(buf *[]byte, d time.Duration) {
+	*buf = append(*buf, d.String()...)
+}
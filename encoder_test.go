@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEncodeFields(t *testing.T) {
+	fields := Fields{
+		"int":      -3,
+		"uint":     uint8(7),
+		"float32":  float32(1.1),
+		"float64":  2.5,
+		"inf":      math.Inf(1),
+		"bool":     true,
+		"nil":      nil,
+		"string":   "a b",
+		"error":    errors.New("oops"),
+		"time":     time.Date(2015, time.July, 2, 13, 28, 42, 500000000, time.UTC),
+		"duration": 1500 * time.Millisecond,
+		"other":    []int{1, 2},
+		"http":     Fields{"method": "GET", "client": Fields{"ip": "10.0.0.1"}},
+	}
+	cases := []struct {
+		enc      Encoder
+		expected string
+	}{
+		{TextEncoder{}, ` bool=true duration=1.5s error=oops float32=1.1 float64=2.5 http.client.ip=10.0.0.1` +
+			` http.method=GET inf=+Inf int=-3 nil=<nil> other="[1 2]" string="a b"` +
+			` time=2015-07-02T13:28:42.5Z uint=7`},
+		{JSONEncoder{}, `{"bool":true,"duration":"1.5s","error":"oops","float32":1.1,"float64":2.5,` +
+			`"http":{"client":{"ip":"10.0.0.1"},"method":"GET"},"inf":"+Inf","int":-3,"nil":null,` +
+			`"other":"[1 2]","string":"a b","time":"2015-07-02T13:28:42.5Z","uint":7`},
+	}
+	for _, c := range cases {
+		var buf []byte
+		if _, ok := c.enc.(JSONEncoder); ok {
+			buf = append(buf, '{')
+		}
+		EncodeFields(c.enc, &buf, fields)
+		if string(buf) != c.expected {
+			t.Errorf("Expected %T to write %q, got %q instead\n", c.enc, c.expected, buf)
+		}
+	}
+}
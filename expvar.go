@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+)
+
+var (
+	// expvarMu guards expvarMaps, and makes checking for a name and publishing it atomic.
+	expvarMu sync.Mutex
+	// expvarMaps holds the maps published by WithExpvar, by name.
+	expvarMaps = map[string]*expvar.Map{}
+)
+
+// expvarHook is a Hook that counts entries by Level in an expvar.Map.
+type expvarHook struct {
+	counts *expvar.Map
+}
+
+// Fire increments the count for e's Level.
+func (h expvarHook) Fire(e *Entry) {
+	h.counts.Add(string(e.Level), 1)
+}
+
+// WithExpvar publishes the number of entries the Logger writes at each Level as an expvar.Map named
+// name, so they show up at /debug/vars. Loggers created with the same name share the map, and their
+// counts are added together; use different names to count them separately. It is an error if name is
+// already published by something other than WithExpvar.
+func WithExpvar(name string) Option {
+	return func(l *Logger) error {
+		expvarMu.Lock()
+		counts, ok := expvarMaps[name]
+		if !ok {
+			if v := expvar.Get(name); v != nil {
+				expvarMu.Unlock()
+				return fmt.Errorf("expvar %q is already published as a %T", name, v)
+			}
+			counts = expvar.NewMap(name)
+			expvarMaps[name] = counts
+		}
+		expvarMu.Unlock()
+		for _, lvl := range []Level{DebugLvl, InfoLvl, WarnLvl, ErrorLvl} {
+			counts.Add(string(lvl), 0)
+		}
+		l.AddHook(expvarHook{counts: counts})
+		return nil
+	}
+}
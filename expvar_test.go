@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"expvar"
+	"io/ioutil"
+	"sync"
+	"testing"
+)
+
+func TestWithExpvar(t *testing.T) {
+	first, err := NewWithOptions(WithOutput(ioutil.Discard), WithExpvar("test_log_counts"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	second, err := NewWithOptions(WithOutput(ioutil.Discard), WithExpvar("test_log_counts"))
+	if err != nil {
+		t.Fatalf("Expected a second Logger to share the map, got %+v instead\n", err)
+	}
+	first.Debug("suppressed")
+	first.Info("one")
+	second.Info("two")
+	second.Error("three")
+	counts := expvar.Get("test_log_counts").(*expvar.Map)
+	expected := map[string]string{"DEBUG": "0", "INFO": "2", "WARN": "0", "ERROR": "1"}
+	for k, v := range expected {
+		if got := counts.Get(k).String(); got != v {
+			t.Errorf("Expected %s to be %s, got %s instead\n", k, v, got)
+		}
+	}
+
+	expvar.NewInt("test_log_counts_int")
+	if _, err := NewWithOptions(WithExpvar("test_log_counts_int")); err == nil {
+		t.Error("Expected an error when the name is taken by another type")
+	}
+
+	expvar.NewMap("test_log_counts_map")
+	if _, err := NewWithOptions(WithExpvar("test_log_counts_map")); err == nil {
+		t.Error("Expected an error when the name is taken by a map WithExpvar didn't publish")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := NewWithOptions(WithOutput(ioutil.Discard), WithExpvar("test_log_counts_racing")); err != nil {
+				t.Errorf("Unexpected error: %+v\n", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
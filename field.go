@@ -0,0 +1,305 @@
+package logging
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// fieldKind identifies how a Field's value is stored.
+type fieldKind uint8
+
+const (
+	skipField fieldKind = iota
+	stringField
+	intField
+	floatField
+	boolField
+	durationField
+	anyField
+)
+
+// Field is a typed key/value pair attached to a single entry, made with one of the constructors like
+// String or Int and passed to a method like Infow. Values are stored by type, so they aren't boxed in
+// an interface{} unless the entry is actually written, and are rendered with their proper type by
+// formatters like JSONFormatter.
+type Field struct {
+	Key  string
+	kind fieldKind
+	num  int64
+	str  string
+	any  interface{}
+}
+
+// String returns a Field holding a string.
+func String(key, val string) Field {
+	return Field{Key: key, kind: stringField, str: val}
+}
+
+// Int returns a Field holding an int.
+func Int(key string, val int) Field {
+	return Field{Key: key, kind: intField, num: int64(val)}
+}
+
+// Int64 returns a Field holding an int64.
+func Int64(key string, val int64) Field {
+	return Field{Key: key, kind: intField, num: val}
+}
+
+// Float64 returns a Field holding a float64.
+func Float64(key string, val float64) Field {
+	return Field{Key: key, kind: floatField, num: int64(math.Float64bits(val))}
+}
+
+// Bool returns a Field holding a bool.
+func Bool(key string, val bool) Field {
+	f := Field{Key: key, kind: boolField}
+	if val {
+		f.num = 1
+	}
+	return f
+}
+
+// Duration returns a Field holding a time.Duration. It is written like "1.5s", or as a number of the
+// Logger's duration unit, if it has one; see SetDurationUnit.
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, kind: durationField, num: int64(val)}
+}
+
+// Time returns a Field holding a time.Time. It is written in RFC 3339 format, with as many fractional
+// seconds as needed, and in UTC if the Logger converts timestamps to UTC.
+func Time(key string, val time.Time) Field {
+	return Field{Key: key, kind: anyField, any: val}
+}
+
+// Err returns a Field with the key "error", holding err's message. If err is nil, the Field is left out
+// of the entry.
+func Err(err error) Field {
+	if err == nil {
+		return Field{kind: skipField}
+	}
+	return Field{Key: "error", kind: stringField, str: err.Error()}
+}
+
+// Any returns a Field holding val, of any type. Prefer one of the typed constructors when the type is
+// known.
+func Any(key string, val interface{}) Field {
+	return Field{Key: key, kind: anyField, any: val}
+}
+
+// Value returns the Field's value, as the type it was constructed with.
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case stringField:
+		return f.str
+	case intField:
+		return f.num
+	case floatField:
+		return math.Float64frombits(uint64(f.num))
+	case boolField:
+		return f.num == 1
+	case durationField:
+		return time.Duration(f.num)
+	case anyField:
+		return f.any
+	default:
+		return nil
+	}
+}
+
+// logw writes an entry at lvl with msg as the message and fields attached to it alone. The fields are
+// kept as they are on the Entry, rather than being merged into the Logger's Fields, so formatters that
+// encode them by type don't box them. See write.
+func (l *Logger) logw(lvl Level, msg string, fields []Field) {
+	if strings.HasSuffix(msg, "\n") {
+		// Keep the message as Info would write it, without boxing msg for join in the common case.
+		msg = l.join(msg)
+	}
+	err := l.outputAt(l.calldepth+3, 0, time.Time{}, msg, lvl, fields)
+	if err != nil {
+		l.handleError(err)
+	}
+}
+
+// encodesTyped reports whether the typed fields of an entry can be left for formatter to encode. They are
+// merged into the entry's Fields instead for hooks, which only see Fields, for sinks, whose Formatters
+// may not encode them, and for groups, which nest them with the Logger's own Fields.
+func (l *Logger) encodesTyped(formatter Formatter, typed []Field) bool {
+	if _, ok := formatter.(typedEncoder); !ok {
+		return false
+	}
+	if l.hooks != nil || len(l.groups) > 0 || atomic.LoadInt32(&l.teed) == 1 {
+		return false
+	}
+	for _, f := range typed {
+		if _, ok := f.any.(Fields); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeTyped returns fields merged with the typed fields of an entry, under the Logger's groups, for
+// formatters and hooks that only see an Entry's Fields.
+func (l *Logger) mergeTyped(fields Fields, typed []Field) Fields {
+	added := make(Fields, len(typed))
+	for _, f := range typed {
+		if f.kind != skipField {
+			added[f.Key] = f.Value()
+		}
+	}
+	return fields.merge(l.grouped(added))
+}
+
+// formatTyped is formatTimes for typed fields: durations are converted to the Logger's duration unit, if
+// it has one, and times to UTC, if it converts timestamps to UTC. Times are otherwise left to the
+// Encoder, which writes them in the same format as formatTimes. fields is returned if nothing changes.
+func (l *Logger) formatTyped(fields []Field) []Field {
+	if l.durationUnit == 0 && !l.utc {
+		return fields
+	}
+	var formatted []Field
+	for i, f := range fields {
+		switch f.kind {
+		case durationField:
+			if l.durationUnit == 0 {
+				continue
+			}
+			f = l.inDurationUnit(f.Key, time.Duration(f.num))
+		case anyField:
+			switch v := f.any.(type) {
+			case time.Duration:
+				if l.durationUnit == 0 {
+					continue
+				}
+				f = l.inDurationUnit(f.Key, v)
+			case time.Time:
+				if !l.utc {
+					continue
+				}
+				f = Time(f.Key, v.UTC())
+			default:
+				continue
+			}
+		default:
+			continue
+		}
+		if formatted == nil {
+			formatted = append([]Field(nil), fields...)
+		}
+		formatted[i] = f
+	}
+	if formatted == nil {
+		return fields
+	}
+	return formatted
+}
+
+// inDurationUnit returns a Field holding d as a number of the Logger's duration unit, an integer if it
+// divides evenly.
+func (l *Logger) inDurationUnit(key string, d time.Duration) Field {
+	if d%l.durationUnit == 0 {
+		return Int64(key, int64(d/l.durationUnit))
+	}
+	return Float64(key, float64(d)/float64(l.durationUnit))
+}
+
+// fieldList returns fields as typed fields, for the wf methods.
+func fieldList(fields Fields) []Field {
+	list := make([]Field, 0, len(fields))
+	for k, v := range fields {
+		list = append(list, Any(k, v))
+	}
+	return list
+}
+
+// The w methods write a message along with Fields that apply to that entry alone, as if they were
+// written by a Child with those Fields. When a key is in both, the Field passed wins.
+
+// Infow writes a log entry with the Level of InfoLvl, with msg as the message and fields attached.
+func (l *Logger) Infow(msg string, fields ...Field) {
+	if !l.enabled(InfoLvl) {
+		return
+	}
+	l.logw(InfoLvl, msg, fields)
+}
+
+// Warnw writes a log entry with the Level of WarnLvl, with msg as the message and fields attached.
+//
+// Any message logged with Warnw will automatically be sent to Sentry, if Sentry
+// has been configured, with fields in the event's extra context. The event is sent in the background;
+// see FlushSentry.
+func (l *Logger) Warnw(msg string, fields ...Field) {
+	if !l.enabled(WarnLvl) {
+		return
+	}
+	l.logw(WarnLvl, msg, fields)
+	l.toSentry("%s", []interface{}{msg}, WarnLvl, fields)
+}
+
+// Errorw writes a log entry with the Level of ErrorLvl, with msg as the message and fields attached.
+//
+// Any message logged with Errorw will automatically be sent to Sentry, if Sentry
+// has been configured, with fields in the event's extra context. The event is sent in the background;
+// see FlushSentry.
+func (l *Logger) Errorw(msg string, fields ...Field) {
+	if !l.enabled(ErrorLvl) {
+		return
+	}
+	l.logw(ErrorLvl, msg, fields)
+	l.toSentry("%s", []interface{}{msg}, ErrorLvl, fields)
+}
+
+// The wf methods combine the f and w methods: the message is made by interpolating the format string
+// with the arguments passed, as with fmt.Sprintf, and fields are attached to that entry alone. The
+// fields come first, so the format string and its arguments read as they would in a call to Infof:
+//
+//	log.Infowf(logging.Fields{"user_id": id}, "user %s logged in from %s", name, addr)
+
+// logwf writes an entry at lvl, interpolating the format string with the arguments passed, with fields
+// attached to it alone.
+func (l *Logger) logwf(fields Fields, format string, lvl Level, args ...interface{}) {
+	err := l.outputAt(l.calldepth+3, 0, time.Time{}, fmt.Sprintf(format, args...), lvl, fieldList(fields))
+	if err != nil {
+		l.handleError(err)
+	}
+}
+
+// Infowf writes a log entry with the Level of InfoLvl, interpolating the format string with the
+// arguments passed, with fields attached.
+func (l *Logger) Infowf(fields Fields, format string, args ...interface{}) {
+	if !l.enabled(InfoLvl) {
+		return
+	}
+	l.logwf(fields, format, InfoLvl, args...)
+}
+
+// Warnwf writes a log entry with the Level of WarnLvl, interpolating the format string with the
+// arguments passed, with fields attached.
+//
+// Any message logged with Warnwf will automatically be sent to Sentry, if Sentry
+// has been configured, with fields in the event's extra context. The event is sent in the background;
+// see FlushSentry.
+func (l *Logger) Warnwf(fields Fields, format string, args ...interface{}) {
+	if !l.enabled(WarnLvl) {
+		return
+	}
+	l.logwf(fields, format, WarnLvl, args...)
+	l.toSentry(format, args, WarnLvl, fieldList(fields))
+}
+
+// Errorwf writes a log entry with the Level of ErrorLvl, interpolating the format string with the
+// arguments passed, with fields attached.
+//
+// Any message logged with Errorwf will automatically be sent to Sentry, if Sentry
+// has been configured, with fields in the event's extra context. The event is sent in the background;
+// see FlushSentry.
+func (l *Logger) Errorwf(fields Fields, format string, args ...interface{}) {
+	if !l.enabled(ErrorLvl) {
+		return
+	}
+	l.logwf(fields, format, ErrorLvl, args...)
+	l.toSentry(format, args, ErrorLvl, fieldList(fields))
+}
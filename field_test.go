@@ -0,0 +1,191 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldValue(t *testing.T) {
+	cases := []struct {
+		field    Field
+		expected interface{}
+	}{
+		{String("k", "v"), "v"},
+		{Int("k", -3), int64(-3)},
+		{Int64("k", 1<<40), int64(1 << 40)},
+		{Float64("k", 1.5), 1.5},
+		{Bool("k", true), true},
+		{Bool("k", false), false},
+		{Duration("k", time.Second), time.Second},
+		{Err(errors.New("boom")), "boom"},
+		{Err(nil), nil},
+		{Any("k", []int{1}), []int{1}},
+	}
+	for _, c := range cases {
+		if got := c.field.Value(); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("Expected %v, got %v instead\n", c.expected, got)
+		}
+	}
+}
+
+func TestInfow(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithFormatter(JSONFormatter{}),
+		WithClock(func() time.Time { return time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC) }))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log = log.Child(Fields{"service": "api", "user": "default"})
+	log.Debugw("suppressed", String("k", "v"))
+	log.Infow("request done", String("user", "bob"), Int("status", 200), Float64("ratio", 0.5),
+		Bool("cached", false), Duration("latency", 1500*time.Millisecond), Err(nil))
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Expected one JSON object, got '%s' instead: %+v\n", buf.String(), err)
+	}
+	delete(got, "caller")
+	expected := map[string]interface{}{
+		"time":    "2015-07-02T13:28:42Z",
+		"level":   "INFO",
+		"msg":     "request done",
+		"service": "api",
+		"user":    "bob",
+		"status":  200.0,
+		"ratio":   0.5,
+		"cached":  false,
+		"latency": "1.5s",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v instead\n", expected, got)
+	}
+	if fields := log.GetFields(); len(fields) != 2 || fields["user"] != "default" {
+		t.Errorf("Expected the Logger's Fields to be unchanged, got %v instead\n", fields)
+	}
+}
+
+func TestInfowFieldOrder(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.Child(Fields{"a": 1, "m": "old", "z": "old"})
+	log.Infow("done", String("z", "new"), Int("b", 1), String("m", "x"), String("b", "two"), Err(nil))
+	expected := "done a=1 b=two m=x z=new\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+
+	buf.Reset()
+	log.SetRedactor(func(key, value string) string {
+		if key == "b" {
+			return "***"
+		}
+		return value
+	})
+	log.Infow("done", Int("b", 1))
+	if !strings.HasSuffix(buf.String(), "done a=1 b=*** m=old z=old\n") {
+		t.Errorf("Expected the typed field to be redacted, got '%s' instead\n", buf.String())
+	}
+
+	hook := &lastEntry{}
+	log.SetRedactor(nil).AddHook(hook)
+	log.Infow("hooked", Int("b", 1))
+	if hook.entry == nil || hook.entry.Fields["b"] != int64(1) || hook.entry.Fields["a"] != 1 {
+		t.Errorf("Expected hooks to see the typed fields in Fields, got %+v instead\n", hook.entry)
+	}
+}
+
+func TestJSONFormatterCaller(t *testing.T) {
+	var buf []byte
+	JSONFormatter{}.Format(&buf, &Entry{
+		Time:      time.Date(2015, time.July, 2, 13, 28, 42, 5, time.UTC),
+		Level:     ErrorLvl,
+		File:      "/my/test/file.go",
+		Line:      145,
+		Message:   "a \"quoted\"\nmessage",
+		Goroutine: 7,
+	})
+	expected := `{"time":"2015-07-02T13:28:42.000000005Z","level":"ERROR","caller":"/my/test/file.go:145","goroutine":7,"msg":"a \"quoted\"\nmessage"}`
+	if string(buf) != expected {
+		t.Errorf("Expected output to be '%s', got '%s' instead\n", expected, buf)
+	}
+}
+
+func TestInfowf(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log = log.Child(Fields{"service": "api"})
+	log.Infowf(Fields{"user_id": 7}, "user %s logged in from %s", "bob", "10.0.0.1")
+	if !strings.HasSuffix(buf.String(), ": user bob logged in from 10.0.0.1 service=api user_id=7\n") {
+		t.Errorf("Expected the message and fields, got '%s' instead\n", buf.String())
+	}
+	if !strings.Contains(buf.String(), "/field_test.go:") {
+		t.Errorf("Expected the caller to be this file, got '%s' instead\n", buf.String())
+	}
+	if _, ok := log.GetFields()["user_id"]; ok {
+		t.Error("Expected the Logger's Fields to be unchanged")
+	}
+}
+
+func TestDurationUnit(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithFormatter(JSONFormatter{}))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	latency := 1234567 * time.Nanosecond
+	cases := []struct {
+		unit     time.Duration
+		expected string
+	}{
+		{0, `"latency":"1.234567ms","timeout":"2s"`},
+		{time.Millisecond, `"latency":1.234567,"timeout":2000`},
+		{time.Second, `"latency":0.001234567,"timeout":2`},
+		{time.Nanosecond, `"latency":1234567,"timeout":2000000000`},
+	}
+	for _, c := range cases {
+		buf.Reset()
+		log.SetDurationUnit(c.unit).Infow("served", Duration("latency", latency), Duration("timeout", 2*time.Second))
+		if !strings.HasSuffix(buf.String(), c.expected+"}\n") {
+			t.Errorf("Expected output to end with '%s' for %v, got '%s' instead\n", c.expected, c.unit, buf.String())
+		}
+	}
+
+	buf.Reset()
+	log.SetDurationUnit(time.Millisecond).WithGroup("http").Child(Fields{"latency": latency}).Info("grouped")
+	if !strings.Contains(buf.String(), `"http":{"latency":1.234567}`) {
+		t.Errorf("Expected durations in groups to be converted, got '%s' instead\n", buf.String())
+	}
+}
+
+func TestTimeField(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	started := time.Date(2015, time.July, 2, 13, 28, 42, 500000000, time.FixedZone("EST", -5*60*60))
+	log.Infow("started", Time("at", started))
+	expected := " at=2015-07-02T13:28:42.5-05:00\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+
+	buf.Reset()
+	log.utc = true
+	log.Infow("started", Time("at", started))
+	expected = " at=2015-07-02T18:28:42.5Z\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+}
@@ -0,0 +1,179 @@
+package logging
+
+import (
+	"sort"
+	"time"
+)
+
+// Fields are key/value pairs of structured context attached to every entry written by a Logger.
+//
+// Every Formatter writes Fields sorted by key, in byte order, rather than in Go's random map order or the
+// order they were added, so the same entry is always rendered the same way, and log output can be
+// compared against golden files.
+type Fields map[string]interface{}
+
+// sortedKeys returns the keys of f in ascending order, so Fields are always rendered deterministically.
+func (f Fields) sortedKeys() []string {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// merge returns a new Fields holding f and other, with the values in other taking precedence. Groups in
+// both, nested Fields under the same key, are merged too.
+func (f Fields) merge(other Fields) Fields {
+	merged := make(Fields, len(f)+len(other))
+	for k, v := range f {
+		merged[k] = v
+	}
+	for k, v := range other {
+		group, ok := v.(Fields)
+		if existing, isGroup := merged[k].(Fields); ok && isGroup {
+			v = existing.merge(group)
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// flatten returns f with the fields of its groups moved to the top level, under their dotted keys, for
+// formats that can't nest values. It returns f itself if it has no groups.
+func (f Fields) flatten() Fields {
+	nested := false
+	for _, v := range f {
+		if _, ok := v.(Fields); ok {
+			nested = true
+			break
+		}
+	}
+	if !nested {
+		return f
+	}
+	flat := make(Fields, len(f))
+	f.flattenInto(flat, "")
+	return flat
+}
+
+// flattenInto adds the fields of f to flat, with their keys prefixed by prefix.
+func (f Fields) flattenInto(flat Fields, prefix string) {
+	for k, v := range f {
+		if group, ok := v.(Fields); ok {
+			group.flattenInto(flat, prefix+k+".")
+		} else {
+			flat[prefix+k] = v
+		}
+	}
+}
+
+// SetDurationUnit makes the Logger write time.Duration field values as a number of unit, like 1.5 for
+// 1.5ms with time.Millisecond, rather than as strings like "1.5ms", so they can be aggregated as numbers
+// downstream. Whole numbers of unit are written as integers. Pass 0 to write durations as strings again,
+// which is the default.
+//
+// Whatever the unit, time.Time field values are always written in RFC 3339 format, rather than in the
+// format of their String method.
+func (l *Logger) SetDurationUnit(unit time.Duration) *Logger {
+	if unit < 0 {
+		unit = 0
+	}
+	l.durationUnit = unit
+	return l
+}
+
+// formatTimes returns fields with its time.Duration values converted to numbers of the Logger's duration
+// unit, if it has one, and its time.Time values converted to RFC 3339 strings, including the values in
+// its groups. Fields are only copied if there is something to convert, and it reports whether they were.
+func (l *Logger) formatTimes(fields Fields) (Fields, bool) {
+	var formatted Fields
+	for k, v := range fields {
+		switch t := v.(type) {
+		case time.Duration:
+			if l.durationUnit == 0 {
+				continue
+			}
+			if t%l.durationUnit == 0 {
+				v = int64(t / l.durationUnit)
+			} else {
+				v = float64(t) / float64(l.durationUnit)
+			}
+		case time.Time:
+			if l.utc {
+				t = t.UTC()
+			}
+			v = t.Format(time.RFC3339Nano)
+		case Fields:
+			group, ok := l.formatTimes(t)
+			if !ok {
+				continue
+			}
+			v = group
+		default:
+			continue
+		}
+		if formatted == nil {
+			formatted = make(Fields, len(fields))
+			for k, v := range fields {
+				formatted[k] = v
+			}
+		}
+		formatted[k] = v
+	}
+	if formatted == nil {
+		return fields, false
+	}
+	return formatted, true
+}
+
+// Child returns a copy of the Logger whose Fields are the Logger's Fields merged with fields. When a key
+// exists in both, the value from fields wins. The Logger's own Fields are never modified, so Child can be
+// called repeatedly to build up context, layer by layer. If the Logger has a group, fields are added to
+// it; see WithGroup.
+func (l *Logger) Child(fields Fields) *Logger {
+	newLogger := l.makeCopy()
+	newLogger.fields = l.fields.merge(l.grouped(fields))
+	return newLogger
+}
+
+// WithGroup returns a copy of the Logger that adds the Fields passed to Child, and to the w and wf
+// methods, to a group called name, rather than at the top level. Fields added before the group was
+// created are unaffected, and calling WithGroup again nests a group within the group:
+//
+//	log.WithGroup("http").Child(logging.Fields{"method": "GET", "status": 200}).Info("served")
+//
+// The JSONFormatter writes a group as a nested object, like "http":{"method":"GET","status":200}, while
+// the TextFormatter, and other formats that can't nest values, write its fields under dotted keys, like
+// http.method=GET http.status=200. An empty name returns the Logger itself.
+func (l *Logger) WithGroup(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	newLogger := l.makeCopy()
+	newLogger.groups = append(l.groups[:len(l.groups):len(l.groups)], name)
+	return newLogger
+}
+
+// grouped returns fields nested in the Logger's groups, or fields itself if it has none.
+func (l *Logger) grouped(fields Fields) Fields {
+	for i := len(l.groups) - 1; i >= 0; i-- {
+		fields = Fields{l.groups[i]: fields}
+	}
+	return fields
+}
+
+// GetFields returns a copy of the Fields attached to the Logger.
+func (l *Logger) GetFields() Fields {
+	fields := make(Fields, len(l.fields))
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	return fields
+}
+
+// appendFields appends fields to buf as space-separated key=value pairs, sorted by key. Values that
+// would be ambiguous unquoted are quoted, and the fields of groups are written under dotted keys.
+func appendFields(buf *[]byte, fields Fields) {
+	EncodeFields(TextEncoder{}, buf, fields)
+}
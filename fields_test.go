@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestChildFields(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	request := log.Child(Fields{"request": "abc123", "layer": "request"})
+	handler := request.Child(Fields{"handler": "users", "layer": "handler"})
+	db := handler.Child(Fields{"table": "users", "layer": "db"})
+
+	if len(log.GetFields()) != 0 {
+		t.Errorf("Expected parent to have no fields, got %+v\n", log.GetFields())
+	}
+	if request.GetFields()["layer"] != "request" {
+		t.Errorf("Expected request layer to be unchanged, got %+v\n", request.GetFields())
+	}
+	if len(handler.GetFields()) != 3 {
+		t.Errorf("Expected handler to have 3 fields, got %+v\n", handler.GetFields())
+	}
+	handler.GetFields()["request"] = "mutated"
+	if handler.GetFields()["request"] != "abc123" {
+		t.Error("Expected GetFields to return a copy")
+	}
+
+	db.Info("querying")
+	expected := `querying handler=users layer=db request=abc123 table=users`
+	if !strings.HasSuffix(buf.String(), ": "+expected+"\n") {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+}
+
+func TestAppendFields(t *testing.T) {
+	fieldTests := map[string]Fields{
+		"":                         nil,
+		" a=1 b=two":               {"b": "two", "a": 1},
+		` empty="" spaced="a b"`:   {"spaced": "a b", "empty": ""},
+		` quoted="say \"hi\"" x=y`: {"x": "y", "quoted": `say "hi"`},
+	}
+	for out, in := range fieldTests {
+		var buf []byte
+		appendFields(&buf, in)
+		if string(buf) != out {
+			t.Errorf("Expected output to be '%s', got '%s' from %+v\n", out, string(buf), in)
+		}
+	}
+}
+
+func TestFieldOrder(t *testing.T) {
+	fields := Fields{}
+	for _, k := range strings.Fields("zulu yankee x-ray whiskey victor uniform tango sierra romeo quebec papa Oscar 9 _") {
+		fields[k] = len(k)
+	}
+	entry := &Entry{Level: InfoLvl, File: "/my/test/file.go", Line: 145, Message: "ordered", Fields: fields}
+	expectedText := " 9=1 Oscar=5 _=1 papa=4 quebec=6 romeo=5 sierra=6 tango=5 uniform=7 victor=6 whiskey=7" +
+		" x-ray=5 yankee=6 zulu=4"
+	expectedJSON := `"9":1,"Oscar":5,"_":1,"papa":4,"quebec":6,"romeo":5,"sierra":6,"tango":5,"uniform":7,` +
+		`"victor":6,"whiskey":7,"x-ray":5,"yankee":6,"zulu":4}`
+
+	// Map iteration order changes between runs, so render the same fields repeatedly.
+	for i := 0; i < 20; i++ {
+		var text, json []byte
+		TextFormatter{}.Format(&text, entry)
+		if !strings.HasSuffix(string(text), expectedText) {
+			t.Fatalf("Expected text output to end with '%s', got '%s' instead\n", expectedText, text)
+		}
+		JSONFormatter{}.Format(&json, entry)
+		if !strings.HasSuffix(string(json), expectedJSON) {
+			t.Fatalf("Expected JSON output to end with '%s', got '%s' instead\n", expectedJSON, json)
+		}
+	}
+}
+
+func TestWithGroup(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	request := log.Child(Fields{"request": "abc123"})
+	http := request.WithGroup("http").Child(Fields{"method": "GET"}).Child(Fields{"status": 200})
+	if request.WithGroup("") != request {
+		t.Error("Expected an empty group name to return the Logger itself")
+	}
+
+	http.Info("served")
+	expected := "served http.method=GET http.status=200 request=abc123"
+	if !strings.HasSuffix(buf.String(), ": "+expected+"\n") {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+
+	buf.Reset()
+	http.WithGroup("client").Infow("served", String("ip", "10.0.0.1"))
+	expected = "served http.client.ip=10.0.0.1 http.method=GET http.status=200 request=abc123"
+	if !strings.HasSuffix(buf.String(), ": "+expected+"\n") {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+
+	buf.Reset()
+	http.formatter = JSONFormatter{}
+	http.Infowf(Fields{"status": 404}, "not found")
+	expected = `"msg":"not found","http":{"method":"GET","status":404},"request":"abc123"}`
+	if !strings.HasSuffix(buf.String(), expected+"\n") {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+
+	buf.Reset()
+	http.SetRedactor(func(key, value string) string {
+		if key == "method" {
+			return Redacted
+		}
+		return value
+	}).Info("redacted")
+	expected = `"http":{"method":"` + Redacted + `","status":200}`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Expected output to contain '%s', got '%s' instead\n", expected, buf.String())
+	}
+}
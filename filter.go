@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"regexp"
+)
+
+// filter is a pattern added with AddFilter.
+type filter struct {
+	re   *regexp.Regexp
+	drop bool
+}
+
+// AddFilter adds a filter that decides whether messages matching re are written, so noise that can't be
+// silenced at its source, like a chatty library logging through StdLogger, can be dropped. If drop is
+// true, matching messages are dropped; if it is false, matching messages are kept.
+//
+// Filters are checked against the message, before it is formatted, in the order they were added, and the
+// first one that matches decides. A message that matches no filter is written, unless at least one filter
+// keeps matches, in which case only messages matched by a keeping filter are written. So to drop most of
+// a group of messages but keep a few, add the keeping filter for the few first.
+func (l *Logger) AddFilter(re *regexp.Regexp, drop bool) *Logger {
+	filters := make([]filter, len(l.filters), len(l.filters)+1)
+	copy(filters, l.filters)
+	l.filters = append(filters, filter{re: re, drop: drop})
+	return l
+}
+
+// filtered reports whether msg should be dropped according to the Logger's filters.
+func (l *Logger) filtered(msg string) bool {
+	keepOnly := false
+	for _, f := range l.filters {
+		if f.re.MatchString(msg) {
+			return f.drop
+		}
+		if !f.drop {
+			keepOnly = true
+		}
+	}
+	return keepOnly
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestAddFilter(t *testing.T) {
+	cases := []struct {
+		name     string
+		filters  []filter
+		expected string
+	}{
+		{
+			name:     "none",
+			expected: "INFO: keep me\nINFO: noisy chatter\nINFO: noisy but important\n",
+		},
+		{
+			name:     "drop",
+			filters:  []filter{{re: regexp.MustCompile("^noisy"), drop: true}},
+			expected: "INFO: keep me\n",
+		},
+		{
+			name:     "keep",
+			filters:  []filter{{re: regexp.MustCompile("noisy")}},
+			expected: "INFO: noisy chatter\nINFO: noisy but important\n",
+		},
+		{
+			name: "first match wins",
+			filters: []filter{
+				{re: regexp.MustCompile("important")},
+				{re: regexp.MustCompile("^noisy"), drop: true},
+			},
+			expected: "INFO: noisy but important\n",
+		},
+		{
+			name: "drop before keep",
+			filters: []filter{
+				{re: regexp.MustCompile("^noisy"), drop: true},
+				{re: regexp.MustCompile("important")},
+			},
+			expected: "",
+		},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		log, err := NewWithOptions(WithOutput(&buf), WithFormatter(upperFormatter{}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v\n", err)
+		}
+		for _, f := range c.filters {
+			log.AddFilter(f.re, f.drop)
+		}
+		log.Info("keep me")
+		log.Info("noisy chatter")
+		log.Info("noisy but important")
+		if buf.String() != c.expected {
+			t.Errorf("%s: Expected output to be '%s', got '%s' instead\n", c.name, c.expected, buf.String())
+		}
+	}
+}
+
+func TestAddFilterChild(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithFormatter(upperFormatter{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	child := log.Child(nil)
+	child.AddFilter(regexp.MustCompile("."), true)
+	log.Info("parent")
+	child.Info("child")
+	if buf.String() != "INFO: parent\n" {
+		t.Errorf("Expected the child's filter to leave the parent alone, got '%s' instead\n", buf.String())
+	}
+}
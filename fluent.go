@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"io"
+	"strconv"
+	"sync"
+)
+
+// FluentFormatter renders an Entry as a Fluentd Forward protocol event: a MessagePack array of its time,
+// as an EventTime, and a record map. The record holds the entry's level, message, and caller, its
+// goroutine if recorded, and the Logger's Fields, with numbers and booleans kept as MessagePack numbers
+// and booleans. Fields named like the record's own keys, "level", "message", "caller", and "goroutine",
+// are written with a leading underscore, like "_level", so the map holds no duplicate keys.
+//
+// Its output is binary, so it must be used with an empty line terminator, and written to a FluentWriter.
+// It is usually used through LogToFluent.
+type FluentFormatter struct{}
+
+// Format appends e to buf as a Forward protocol event.
+func (f FluentFormatter) Format(buf *[]byte, e *Entry) {
+	appendMsgpackArrayHeader(buf, 2)
+	appendMsgpackEventTime(buf, e.Time)
+	fields := fluentFields(e.Fields)
+	n := 2 + len(fields)
+	if e.File != "" {
+		n++
+	}
+	if e.Goroutine != 0 {
+		n++
+	}
+	appendMsgpackMapHeader(buf, n)
+	appendMsgpackString(buf, "level")
+	appendMsgpackString(buf, string(e.Level))
+	appendMsgpackString(buf, "message")
+	appendMsgpackString(buf, e.Message)
+	if e.File != "" {
+		appendMsgpackString(buf, "caller")
+		appendMsgpackString(buf, e.File+":"+strconv.Itoa(e.Line))
+	}
+	if e.Goroutine != 0 {
+		appendMsgpackString(buf, "goroutine")
+		appendMsgpackUint(buf, e.Goroutine)
+	}
+	EncodeFields(msgpackEncoder{}, buf, fields)
+}
+
+// fluentRecordKeys are the keys a FluentFormatter writes in the record itself.
+var fluentRecordKeys = [...]string{"level", "message", "caller", "goroutine"}
+
+// fluentFields returns fields with those named like one of fluentRecordKeys renamed with leading
+// underscores, as many as it takes to find a key that isn't taken. It returns fields itself if none are.
+func fluentFields(fields Fields) Fields {
+	var renamed Fields
+	for _, k := range fluentRecordKeys {
+		v, ok := fields[k]
+		if !ok {
+			continue
+		}
+		if renamed == nil {
+			renamed = make(Fields, len(fields))
+			for k, v := range fields {
+				renamed[k] = v
+			}
+		}
+		delete(renamed, k)
+		name := "_" + k
+		for {
+			if _, taken := renamed[name]; !taken {
+				break
+			}
+			name = "_" + name
+		}
+		renamed[name] = v
+	}
+	if renamed == nil {
+		return fields
+	}
+	return renamed
+}
+
+// FluentWriter is an io.WriteCloser that sends events rendered by a FluentFormatter to a Fluentd or
+// Fluent Bit aggregator, using the Forward protocol's PackedForward mode. Each Write may hold one or more
+// whole events, which are sent together under the writer's tag as a single message, so wrapping a
+// FluentWriter in a BatchWriter sends many events per message. It is an EntryWriter, so SetFlushInterval
+// leaves it alone; wrap it in a BatchWriter explicitly to batch events. It is safe for concurrent use.
+type FluentWriter struct {
+	out io.Writer
+	tag string
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewFluentWriter returns a FluentWriter that sends events to out, like a NetWriter connected to the
+// aggregator's forward input, under tag.
+func NewFluentWriter(out io.Writer, tag string) *FluentWriter {
+	return &FluentWriter{out: out, tag: tag}
+}
+
+// LogToFluent creates a new Logger that sends entries to the Fluentd or Fluent Bit aggregator at addr,
+// over network, tagged with tag. Entries are batched for up to DefaultBatchInterval, and sent with the
+// Forward protocol's PackedForward mode, with the Logger's Fields in each record. The connection is
+// buffered and restored like a NetWriter's, and is closed by the Logger's Close method.
+//
+// If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
+// are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
+// the version of your software running, etc.
+func LogToFluent(level Level, network, addr, tag string, sentry string, sentryTags map[string]string) (*Logger, error) {
+	conn, err := NewNetWriter(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	out := NewBatchWriter(NewFluentWriter(conn, tag), 0, 0)
+	l, err := NewWithOptions(WithLevel(level), WithOutput(out), WithFormatter(FluentFormatter{}),
+		WithSentry(sentry, sentryTags))
+	if err != nil {
+		return l, err
+	}
+	return l.SetLineTerminator(""), nil
+}
+
+// WritesEntries reports that each Write is sent as a separate message. See EntryWriter.
+func (w *FluentWriter) WritesEntries() bool {
+	return true
+}
+
+// Write sends the events in p as a single PackedForward message.
+func (w *FluentWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = w.buf[:0]
+	appendMsgpackArrayHeader(&w.buf, 2)
+	appendMsgpackString(&w.buf, w.tag)
+	appendMsgpackBinHeader(&w.buf, len(p))
+	w.buf = append(w.buf, p...)
+	if _, err := w.out.Write(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush flushes the wrapped io.Writer, if it has a Flush method.
+func (w *FluentWriter) Flush() error {
+	if flusher, ok := w.out.(interface {
+		Flush() error
+	}); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Close closes the wrapped io.Writer, if it is an io.Closer.
+func (w *FluentWriter) Close() error {
+	if closer, ok := w.out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFluentFormatter(t *testing.T) {
+	e := &Entry{
+		Time:    time.Unix(1435843722, 5),
+		Level:   WarnLvl,
+		File:    "f.go",
+		Line:    7,
+		Message: "hi",
+		Fields:  Fields{"n": 1},
+	}
+	var buf []byte
+	FluentFormatter{}.Format(&buf, e)
+	expected := []byte{0x92, 0xd7, 0x00, 0x55, 0x95, 0x3c, 0x8a, 0x00, 0x00, 0x00, 0x05, 0x84}
+	expected = append(expected, 0xa5)
+	expected = append(expected, "level"...)
+	expected = append(expected, 0xa4)
+	expected = append(expected, "WARN"...)
+	expected = append(expected, 0xa7)
+	expected = append(expected, "message"...)
+	expected = append(expected, 0xa2, 'h', 'i', 0xa6)
+	expected = append(expected, "caller"...)
+	expected = append(expected, 0xa6)
+	expected = append(expected, "f.go:7"...)
+	expected = append(expected, 0xa1, 'n', 0x01)
+	if !bytes.Equal(buf, expected) {
+		t.Errorf("Expected % x, got % x instead\n", expected, buf)
+	}
+}
+
+func TestFluentFormatterRecordKeys(t *testing.T) {
+	e := &Entry{
+		Time:    time.Unix(1435843722, 5),
+		Level:   WarnLvl,
+		Message: "hi",
+		Fields:  Fields{"level": "x", "_level": 1},
+	}
+	var buf []byte
+	FluentFormatter{}.Format(&buf, e)
+	expected := []byte{0x92, 0xd7, 0x00, 0x55, 0x95, 0x3c, 0x8a, 0x00, 0x00, 0x00, 0x05, 0x84}
+	expected = append(expected, 0xa5)
+	expected = append(expected, "level"...)
+	expected = append(expected, 0xa4)
+	expected = append(expected, "WARN"...)
+	expected = append(expected, 0xa7)
+	expected = append(expected, "message"...)
+	expected = append(expected, 0xa2, 'h', 'i', 0xa7)
+	expected = append(expected, "__level"...)
+	expected = append(expected, 0xa1, 'x', 0xa6)
+	expected = append(expected, "_level"...)
+	expected = append(expected, 0x01)
+	if !bytes.Equal(buf, expected) {
+		t.Errorf("Expected % x, got % x instead\n", expected, buf)
+	}
+	if _, ok := e.Fields["level"]; !ok {
+		t.Error("Expected the Entry's Fields to be unchanged")
+	}
+}
+
+func TestFluentWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := NewFluentWriter(&out, "app")
+	if n, err := w.Write([]byte{0x01, 0x02}); err != nil || n != 2 {
+		t.Fatalf("Expected to write 2 bytes, got %d and %v instead\n", n, err)
+	}
+	expected := []byte{0x92, 0xa3, 'a', 'p', 'p', 0xc4, 0x02, 0x01, 0x02}
+	if !bytes.Equal(out.Bytes(), expected) {
+		t.Errorf("Expected % x, got % x instead\n", expected, out.Bytes())
+	}
+}
+
+func TestLogToFluent(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer ln.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		b, _ := ioutil.ReadAll(conn)
+		received <- b
+	}()
+
+	log, err := LogToFluent(DebugLvl, "tcp", ln.Addr().String(), "app", "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Info("first")
+	log.Info("second")
+	if err := log.Close(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	b := <-received
+	header := []byte{0x92, 0xa3, 'a', 'p', 'p', 0xc4}
+	if !bytes.HasPrefix(b, header) {
+		t.Fatalf("Expected a PackedForward message, got % x instead\n", b)
+	}
+	if n := int(b[len(header)]); n != len(b)-len(header)-1 {
+		t.Errorf("Expected %d bytes of events, got %d instead\n", len(b)-len(header)-1, n)
+	}
+	if bytes.Count(b, []byte{0x92, 0xd7, 0x00}) != 2 || !bytes.Contains(b, []byte("first")) || !bytes.Contains(b, []byte("second")) {
+		t.Errorf("Expected both events in a single message, got % x instead\n", b)
+	}
+}
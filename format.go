@@ -0,0 +1,275 @@
+package logging
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// levelWidth is the width of the widest Level, used to align levels in the header.
+const levelWidth = len(ErrorLvl)
+
+// Entry is a single log statement, as handed to a Formatter. Message never includes a trailing newline;
+// the Logger terminates each formatted Entry itself, with the string set by SetLineTerminator.
+type Entry struct {
+	Time  time.Time
+	Level Level
+	// File and Line locate the call that created the Entry. File is empty if the caller wasn't looked up,
+	// because of SetCallerLevel, in which case Formatters leave the caller out.
+	File    string
+	Line    int
+	Message string
+	Fields  Fields
+	// Goroutine is the ID of the goroutine that created the Entry, or 0 if the Logger was not asked to
+	// record it. See SetIncludeGoroutineID.
+	Goroutine uint64
+
+	// typed holds the Fields passed to a w method for this entry alone, which override Fields with the
+	// same key. It is only set for the Formatters that encode it; see typedEncoder.
+	typed []Field
+}
+
+// Formatter controls how an Entry is rendered before it is written to a Logger's output.
+type Formatter interface {
+	// Format appends the rendered Entry to buf. It should not append a trailing newline. The Entry's
+	// Message may share memory with the caller's arguments, so Format must copy it if it is kept after
+	// Format returns.
+	Format(buf *[]byte, e *Entry)
+}
+
+// TextFormatter renders an Entry as a single human-readable line, in the form
+//
+//	2015-07-02T13:28:42 [WARN] /my/test/file.go:145: message key=value
+//
+// It is the Formatter used when no other is specified.
+type TextFormatter struct {
+	// AlignLevels pads the level in the header to a fixed width, so the columns after it line up.
+	AlignLevels bool
+	// CompactLevel writes the level in the header as a single character (D, I, W, or E) without
+	// brackets, instead of the full word. It takes precedence over AlignLevels.
+	CompactLevel bool
+	// Epoch writes the time in the header as a number of units since the Unix epoch, instead of as a
+	// date and time.
+	Epoch EpochUnit
+	// TimePrecision is the number of digits of fractional seconds written after the time in the header,
+	// from 0 (the default) to 9. It is ignored when Epoch is set.
+	TimePrecision int
+	// TrimPrefixes are stripped from the start of the caller's file path in the header, so paths are
+	// relative to the build root. The first prefix that matches is used.
+	TrimPrefixes []string
+	// Color wraps the level in the header in ANSI escape codes, colored by severity, for reading on a
+	// terminal. See SetColorAuto.
+	Color bool
+	// Icons maps each Level to a glyph, like an emoji, written with a space at the start of the line.
+	// Levels without an icon get none. See SetLevelIcons.
+	Icons map[Level]string
+	// Header, if set, arranges the components of the header and the message, in place of the standard
+	// DefaultHeaderTemplate. See ParseHeaderTemplate.
+	Header *HeaderTemplate
+}
+
+// EpochUnit is the unit used when writing times as a number since the Unix epoch.
+type EpochUnit int
+
+const (
+	// EpochNone writes times as a date and time, like 2015-07-02T13:28:42. It is the default.
+	EpochNone EpochUnit = iota
+	// EpochSeconds writes times as whole seconds since the Unix epoch.
+	EpochSeconds
+	// EpochMillis writes times as milliseconds since the Unix epoch.
+	EpochMillis
+	// EpochMicros writes times as microseconds since the Unix epoch.
+	EpochMicros
+	// EpochNanos writes times as nanoseconds since the Unix epoch.
+	EpochNanos
+)
+
+// since returns the number of units between the Unix epoch and t.
+func (u EpochUnit) since(t time.Time) int64 {
+	switch u {
+	case EpochMillis:
+		return t.UnixNano() / int64(time.Millisecond)
+	case EpochMicros:
+		return t.UnixNano() / int64(time.Microsecond)
+	case EpochNanos:
+		return t.UnixNano()
+	default:
+		return t.Unix()
+	}
+}
+
+// Format appends the header, message, and fields of e to buf. Fields are written after the message as
+// key=value pairs, sorted by key.
+func (f TextFormatter) Format(buf *[]byte, e *Entry) {
+	if f.Header != nil {
+		f.formatTemplate(buf, e)
+		return
+	}
+	f.formatHeader(buf, e.Time, e.File, e.Line, e.Level)
+	f.formatMessage(buf, e)
+	encodeEntryFields(TextEncoder{}, buf, e)
+}
+
+// formatMessage appends the goroutine ID of e, if it was recorded, and its message to buf.
+func (f TextFormatter) formatMessage(buf *[]byte, e *Entry) {
+	if e.Goroutine != 0 {
+		*buf = append(*buf, 'g')
+		itoa(buf, int(e.Goroutine), -1)
+		*buf = append(*buf, ' ')
+	}
+	*buf = append(*buf, e.Message...)
+}
+
+// formatTime appends now to buf, in the format the TextFormatter is configured for.
+func (f TextFormatter) formatTime(buf *[]byte, now time.Time) {
+	if f.Epoch != EpochNone {
+		*buf = strconv.AppendInt(*buf, f.Epoch.since(now), 10)
+		return
+	}
+	year, month, day := now.Date()
+	itoa(buf, year, 4)
+	*buf = append(*buf, '-')
+	itoa(buf, int(month), 2)
+	*buf = append(*buf, '-')
+	itoa(buf, day, 2)
+	*buf = append(*buf, 'T')
+	hour, minute, second := now.Clock()
+	itoa(buf, hour, 2)
+	*buf = append(*buf, ':')
+	itoa(buf, minute, 2)
+	*buf = append(*buf, ':')
+	itoa(buf, second, 2)
+	if f.TimePrecision > 0 {
+		digits := f.TimePrecision
+		if digits > 9 {
+			digits = 9
+		}
+		frac := now.Nanosecond()
+		for i := digits; i < 9; i++ {
+			frac /= 10
+		}
+		*buf = append(*buf, '.')
+		itoa(buf, frac, digits)
+	}
+}
+
+// trimPath strips the first matching TrimPrefixes entry from file.
+func (f TextFormatter) trimPath(file string) string {
+	for _, prefix := range f.TrimPrefixes {
+		if strings.HasPrefix(file, prefix) {
+			return file[len(prefix):]
+		}
+	}
+	return file
+}
+
+// initial returns the single character used to represent the Level in compact headers.
+func (l Level) initial() byte {
+	if l == "" {
+		return '?'
+	}
+	return l[0]
+}
+
+// Prepend our log header to the buffer.
+//
+// Heavily modified form of https://github.com/golang/go/blob/883bc6ed0ea815293fe6309d66f967ea60630e87/src/log/log.go#L80
+func (f TextFormatter) formatHeader(buf *[]byte, now time.Time, file string, line int, level Level) {
+	if icon := f.Icons[level]; icon != "" {
+		*buf = append(*buf, icon...)
+		*buf = append(*buf, ' ')
+	}
+	f.formatTime(buf, now)
+	*buf = append(*buf, ' ')
+	f.formatLevel(buf, level)
+	*buf = append(*buf, ' ')
+
+	if file == "" {
+		return
+	}
+	*buf = append(*buf, f.trimPath(file)...)
+	*buf = append(*buf, ':')
+	itoa(buf, line, -1)
+	*buf = append(*buf, ": "...)
+}
+
+// formatLevel appends level to buf, in the form the TextFormatter is configured for.
+func (f TextFormatter) formatLevel(buf *[]byte, level Level) {
+	color := ""
+	if f.Color {
+		color = levelColors[level]
+	}
+	*buf = append(*buf, color...)
+	if f.CompactLevel {
+		*buf = append(*buf, level.initial())
+	} else {
+		*buf = append(*buf, "["+string(level)...)
+		if f.AlignLevels {
+			for i := len(level); i < levelWidth; i++ {
+				*buf = append(*buf, ' ')
+			}
+		}
+		*buf = append(*buf, ']')
+	}
+	if color != "" {
+		*buf = append(*buf, colorReset...)
+	}
+}
+
+// withTextFormatter calls fn to modify the Logger's TextFormatter, and returns the Logger. If the Logger
+// doesn't use a TextFormatter, it is left unchanged.
+func (l *Logger) withTextFormatter(fn func(*TextFormatter)) *Logger {
+	f, ok := l.formatter.(TextFormatter)
+	if !ok {
+		return l
+	}
+	fn(&f)
+	l.formatter = f
+	return l
+}
+
+// SetAlignLevels controls whether the level in the header is padded to a fixed width, so that columns
+// line up when reading logs by eye. It is off by default, and has no effect unless the Logger uses a
+// TextFormatter.
+func (l *Logger) SetAlignLevels(align bool) *Logger {
+	return l.withTextFormatter(func(f *TextFormatter) {
+		f.AlignLevels = align
+	})
+}
+
+// SetCompactLevel controls whether the level in the header is written as a single character (D, I, W, or
+// E) instead of the full word, to shrink high-volume logs. It is off by default, and has no effect unless
+// the Logger uses a TextFormatter.
+func (l *Logger) SetCompactLevel(compact bool) *Logger {
+	return l.withTextFormatter(func(f *TextFormatter) {
+		f.CompactLevel = compact
+	})
+}
+
+// SetTimeFormatEpoch makes the header write times as a number of units since the Unix epoch, which is
+// cheaper for time-series ingestion to parse. Pass EpochNone to go back to the default date and time.
+// It has no effect unless the Logger uses a TextFormatter.
+func (l *Logger) SetTimeFormatEpoch(unit EpochUnit) *Logger {
+	return l.withTextFormatter(func(f *TextFormatter) {
+		f.Epoch = unit
+	})
+}
+
+// SetTimePrecision sets the number of digits of fractional seconds written after the time in the header,
+// like 3 for milliseconds or 9 for nanoseconds. It defaults to 0, and is capped at 9. It has no effect
+// unless the Logger uses a TextFormatter.
+func (l *Logger) SetTimePrecision(digits int) *Logger {
+	return l.withTextFormatter(func(f *TextFormatter) {
+		f.TimePrecision = digits
+	})
+}
+
+// SetTrimPrefix sets prefixes that are stripped from the start of the caller's file path in the header,
+// like the build root, so /home/ci/src/myrepo/internal/db/query.go is written as internal/db/query.go.
+// When several prefixes are given, the first one that matches is used. Calling SetTrimPrefix with no
+// prefixes writes full paths again. It has no effect unless the Logger uses a TextFormatter.
+func (l *Logger) SetTrimPrefix(prefixes ...string) *Logger {
+	return l.withTextFormatter(func(f *TextFormatter) {
+		f.TrimPrefixes = prefixes
+	})
+}
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GELFFormatter renders an Entry as a Graylog Extended Log Format (GELF) 1.1 JSON object, like
+//
+//	{"version":"1.1","host":"web-1","short_message":"message","timestamp":1435843722.123,"level":4,"_file":"/my/test/file.go","_line":145,"_key":"value"}
+//
+// The caller's file and line, and the Logger's Fields, are written as GELF additional fields, prefixed
+// with an underscore. Multi-line messages are written with their first line as short_message and the
+// whole message as full_message.
+type GELFFormatter struct {
+	// Host is written as the GELF host, the name of the machine that sent the message.
+	Host string
+}
+
+// NewGELFFormatter returns a GELFFormatter whose Host is the name of the current machine, as reported by
+// os.Hostname.
+func NewGELFFormatter() GELFFormatter {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return GELFFormatter{Host: host}
+}
+
+// syslogSeverity returns the syslog severity number, as defined by RFC 5424, that corresponds to the
+// Level.
+func (l Level) syslogSeverity() int {
+	switch l {
+	case DebugLvl:
+		return 7
+	case InfoLvl:
+		return 6
+	case WarnLvl:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// Format appends e to buf as a GELF JSON object.
+func (f GELFFormatter) Format(buf *[]byte, e *Entry) {
+	*buf = append(*buf, `{"version":"1.1","host":`...)
+	appendJSONString(buf, f.Host)
+	*buf = append(*buf, `,"short_message":`...)
+	if i := strings.IndexByte(e.Message, '\n'); i >= 0 {
+		appendJSONString(buf, e.Message[:i])
+		*buf = append(*buf, `,"full_message":`...)
+	}
+	appendJSONString(buf, e.Message)
+	*buf = append(*buf, `,"timestamp":`...)
+	ms := e.Time.UnixNano() / 1e6
+	*buf = strconv.AppendInt(*buf, ms/1000, 10)
+	*buf = append(*buf, '.')
+	itoa(buf, int(ms%1000), 3)
+	*buf = append(*buf, `,"level":`...)
+	itoa(buf, e.Level.syslogSeverity(), -1)
+	if e.File != "" {
+		*buf = append(*buf, `,"_file":`...)
+		appendJSONString(buf, e.File)
+		*buf = append(*buf, `,"_line":`...)
+		itoa(buf, e.Line, -1)
+	}
+	if e.Goroutine != 0 {
+		*buf = append(*buf, `,"_goroutine":`...)
+		*buf = strconv.AppendUint(*buf, e.Goroutine, 10)
+	}
+	fields := e.Fields.flatten()
+	for _, k := range fields.sortedKeys() {
+		*buf = append(*buf, ',')
+		appendJSONString(buf, gelfFieldName(k))
+		*buf = append(*buf, ':')
+		// GELF only allows strings and numbers as field values.
+		if b, ok := fields[k].(bool); ok {
+			appendJSONString(buf, strconv.FormatBool(b))
+		} else {
+			appendJSONValue(buf, fields[k])
+		}
+	}
+	*buf = append(*buf, '}')
+}
+
+// gelfFieldName returns the GELF additional field name for the field key k. GELF only allows letters,
+// digits, underscores, dashes, and dots in field names, so other characters are replaced with
+// underscores. The name "_id" is reserved by Graylog, so the key "id" is written as "__id".
+func gelfFieldName(k string) string {
+	if k == "id" {
+		return "__id"
+	}
+	name := []byte("_" + k)
+	for i := 1; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.') {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGELFFormatter(t *testing.T) {
+	e := &Entry{
+		Time:    time.Date(2015, time.July, 2, 13, 28, 42, 123456789, time.UTC),
+		Level:   WarnLvl,
+		File:    "/my/test/file.go",
+		Line:    145,
+		Message: "first line\nsecond \"line\"",
+		Fields:  Fields{"user": "bob", "count": 3, "id": "abc", "bad key": 1.5, "nan": math.NaN(), "ok": true},
+	}
+	var buf []byte
+	GELFFormatter{Host: "web-1"}.Format(&buf, e)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf, &got); err != nil {
+		t.Fatalf("Expected valid JSON, got %s instead: %+v\n", buf, err)
+	}
+	expected := map[string]interface{}{
+		"version":       "1.1",
+		"host":          "web-1",
+		"short_message": "first line",
+		"full_message":  "first line\nsecond \"line\"",
+		"timestamp":     1435843722.123,
+		"level":         4.0,
+		"_file":         "/my/test/file.go",
+		"_line":         145.0,
+		"_user":         "bob",
+		"_count":        3.0,
+		"__id":          "abc",
+		"_bad_key":      1.5,
+		"_nan":          "NaN",
+		"_ok":           "true",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v instead\n", expected, got)
+	}
+}
+
+func TestGELFFormatterShortMessage(t *testing.T) {
+	var buf []byte
+	GELFFormatter{Host: "web-1"}.Format(&buf, &Entry{Time: time.Unix(0, 0), Level: DebugLvl, Message: "hi\x01"})
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf, &got); err != nil {
+		t.Fatalf("Expected valid JSON, got %s instead: %+v\n", buf, err)
+	}
+	if got["short_message"] != "hi\x01" {
+		t.Errorf("Expected short_message to be %q, got %q instead\n", "hi\x01", got["short_message"])
+	}
+	if _, ok := got["full_message"]; ok {
+		t.Error("Expected no full_message for a single-line message")
+	}
+	if got["level"] != 7.0 {
+		t.Errorf("Expected level to be 7, got %v instead\n", got["level"])
+	}
+}
+
+func TestLevelSyslogSeverity(t *testing.T) {
+	for level, expected := range map[Level]int{DebugLvl: 7, InfoLvl: 6, WarnLvl: 4, ErrorLvl: 3} {
+		if got := level.syslogSeverity(); got != expected {
+			t.Errorf("Expected %s to map to %d, got %d instead\n", level, expected, got)
+		}
+	}
+}
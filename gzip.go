@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"compress/gzip"
+	"os"
+	"sync"
+)
+
+// GzipFile is an io.WriteCloser for a log file that compresses what is written to it with gzip, like
+// "app.log.gz", to save disk space without a separate compression job. It is safe for concurrent use.
+//
+// Compressed output is buffered until the file is flushed or closed, so call the Logger's Flush
+// periodically, or wrap the GzipFile in a writer that does, to bound how much is lost if the process
+// crashes. Close must be called to complete the archive; until it is, the file can only be partially
+// decompressed.
+//
+// If the file already exists, the output is appended to it as another gzip member, which gzip and
+// compress/gzip decompress as one stream. To rotate the file, move it aside and call Reopen, which
+// completes the archive before opening the path again, so each rotated file can be decompressed on its
+// own.
+type GzipFile struct {
+	path string
+
+	mu sync.Mutex
+	f  *os.File
+	zw *gzip.Writer
+}
+
+// OpenGzipFile opens the file at path for appending, creating it if it doesn't exist, and returns a
+// GzipFile that compresses what is written to it.
+func OpenGzipFile(path string) (*GzipFile, error) {
+	g := &GzipFile{path: path}
+	if err := g.open(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// LogToGzipFile creates a new Logger that writes to the file specified by path, compressed with gzip.
+// See GzipFile for details.
+//
+// If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
+// are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
+// the version of your software running, etc.
+func LogToGzipFile(level Level, path string, sentry string, sentryTags map[string]string) (*Logger, error) {
+	g, err := OpenGzipFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(level, g, sentry, sentryTags)
+}
+
+// Write compresses p and writes it to the file.
+func (g *GzipFile) Write(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.zw.Write(p)
+}
+
+// Flush writes everything compressed so far to the file, so it can be decompressed up to this point.
+func (g *GzipFile) Flush() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.zw.Flush()
+}
+
+// Sync flushes the compressed output, and commits the file to stable storage.
+func (g *GzipFile) Sync() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.zw.Flush(); err != nil {
+		return err
+	}
+	return g.f.Sync()
+}
+
+// Reopen completes the archive and closes the file, then opens the path again, starting a new archive.
+// It can be called when the file has been rotated, like from a SIGHUP handler.
+func (g *GzipFile) Reopen() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.close(); err != nil {
+		return err
+	}
+	return g.open()
+}
+
+// Close completes the archive and closes the file.
+func (g *GzipFile) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.close()
+}
+
+// close completes the archive and closes the file, even if completing the archive fails. The caller must
+// hold g.mu.
+func (g *GzipFile) close() error {
+	return combineErrors(g.zw.Close(), g.f.Close())
+}
+
+// open opens the path for appending, and starts a new archive. The caller must hold g.mu, or be the only
+// user of g.
+func (g *GzipFile) open() error {
+	f, err := os.OpenFile(g.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	g.f = f
+	g.zw = gzip.NewWriter(f)
+	return nil
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gunzip returns the decompressed contents of the gzip file at path.
+func gunzip(t *testing.T, path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	contents, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Expected a complete archive, got %+v\n", err)
+	}
+	return string(contents)
+}
+
+func TestGzipFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-logging")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.log.gz")
+
+	log, err := LogToGzipFile(InfoLvl, path, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log.Info("first")
+	if err := log.Flush(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Expected a gzip header after Flush, got %+v\n", err)
+	}
+	partial, _ := ioutil.ReadAll(zr)
+	f.Close()
+	if !strings.HasSuffix(string(partial), "first\n") {
+		t.Errorf("Expected the flushed line, got '%s' instead\n", partial)
+	}
+
+	rotated := path + ".1"
+	if err := os.Rename(path, rotated); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	g := log.out.(*GzipFile)
+	if err := g.Reopen(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log.Info("second")
+	if err := log.Close(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if contents := gunzip(t, rotated); !strings.HasSuffix(contents, "first\n") || strings.Contains(contents, "second") {
+		t.Errorf("Expected only the line written before rotating, got '%s' instead\n", contents)
+	}
+	if contents := gunzip(t, path); !strings.HasSuffix(contents, "second\n") || strings.Contains(contents, "first") {
+		t.Errorf("Expected only the line written after rotating, got '%s' instead\n", contents)
+	}
+
+	g, err = OpenGzipFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	g.Write([]byte("third\n"))
+	if err := g.Close(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if contents := gunzip(t, path); !strings.HasSuffix(contents, "second\nthird\n") {
+		t.Errorf("Expected the appended line after the first, got '%s' instead\n", contents)
+	}
+}
@@ -0,0 +1,131 @@
+package logging
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultHeaderTemplate is the template of the TextFormatter's standard header, like
+//
+//	2015-07-02T13:28:42 [WARN] /my/test/file.go:145: message
+const DefaultHeaderTemplate = "{time} {level} {caller}: {msg}"
+
+// headerToken is a component of the header a HeaderTemplate writes.
+type headerToken uint8
+
+const (
+	timeToken headerToken = iota
+	levelToken
+	callerToken
+	msgToken
+)
+
+var headerTokens = map[string]headerToken{
+	"time":   timeToken,
+	"level":  levelToken,
+	"caller": callerToken,
+	"msg":    msgToken,
+}
+
+// headerPart is a component of a HeaderTemplate and the text written after it.
+type headerPart struct {
+	token headerToken
+	after string
+}
+
+// HeaderTemplate arranges the components of each line a TextFormatter writes. It is made from a string by
+// ParseHeaderTemplate, once, rather than being parsed for every entry.
+type HeaderTemplate struct {
+	before string
+	parts  []headerPart
+}
+
+// ParseHeaderTemplate parses a template for the TextFormatter's header, like
+//
+//	{level} {time} {msg}
+//
+// which writes the level first and leaves out the caller. The components are written in place of
+// {time}, {level}, {caller} (the file and line), and {msg} (the goroutine ID, if recorded, and the
+// message), formatted as the TextFormatter is configured; the rest of the template is written as is.
+// Each component may appear once, and {msg} must be included. The fields of the entry are always written
+// after the template.
+//
+// A component with nothing to write, like the caller when SetCallerLevel skips it, is left out along
+// with the text that follows it, up to the next component.
+func ParseHeaderTemplate(template string) (*HeaderTemplate, error) {
+	t := &HeaderTemplate{}
+	seen := map[headerToken]bool{}
+	rest := template
+	i := strings.IndexByte(rest, '{')
+	if i < 0 {
+		return nil, errors.New("header template has no {msg}: " + template)
+	}
+	t.before, rest = rest[:i], rest[i:]
+	for rest != "" {
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return nil, errors.New("header template has an unclosed '{': " + template)
+		}
+		token, ok := headerTokens[rest[1:end]]
+		if !ok {
+			return nil, errors.New("header template has an unknown component " + rest[:end+1] + ": " + template)
+		}
+		if seen[token] {
+			return nil, errors.New("header template repeats " + rest[:end+1] + ": " + template)
+		}
+		seen[token] = true
+		rest = rest[end+1:]
+		next := strings.IndexByte(rest, '{')
+		if next < 0 {
+			next = len(rest)
+		}
+		t.parts = append(t.parts, headerPart{token: token, after: rest[:next]})
+		rest = rest[next:]
+	}
+	if !seen[msgToken] {
+		return nil, errors.New("header template has no {msg}: " + template)
+	}
+	return t, nil
+}
+
+// SetHeaderTemplate sets the template used to arrange the header and message of each line, like
+// "{level} {time} {caller}: {msg}" to write the level first. See ParseHeaderTemplate for the syntax. It
+// returns an error, and leaves the Logger unchanged, if the template can't be parsed. It has no effect
+// unless the Logger uses a TextFormatter.
+func (l *Logger) SetHeaderTemplate(template string) (*Logger, error) {
+	t, err := ParseHeaderTemplate(template)
+	if err != nil {
+		return l, err
+	}
+	return l.withTextFormatter(func(f *TextFormatter) {
+		f.Header = t
+	}), nil
+}
+
+// formatTemplate appends e to buf as arranged by f.Header, followed by its fields.
+func (f TextFormatter) formatTemplate(buf *[]byte, e *Entry) {
+	if icon := f.Icons[e.Level]; icon != "" {
+		*buf = append(*buf, icon...)
+		*buf = append(*buf, ' ')
+	}
+	*buf = append(*buf, f.Header.before...)
+	for _, part := range f.Header.parts {
+		switch part.token {
+		case timeToken:
+			f.formatTime(buf, e.Time)
+		case levelToken:
+			f.formatLevel(buf, e.Level)
+		case callerToken:
+			if e.File == "" {
+				continue
+			}
+			*buf = append(*buf, f.trimPath(e.File)...)
+			*buf = append(*buf, ':')
+			itoa(buf, e.Line, -1)
+		case msgToken:
+			f.formatMessage(buf, e)
+		}
+		*buf = append(*buf, part.after...)
+	}
+	encodeEntryFields(TextEncoder{}, buf, e)
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestParseHeaderTemplate(t *testing.T) {
+	for _, template := range []string{"", "{time} {level}", "{msg", "{msg} {file}", "{msg} {msg}"} {
+		if _, err := ParseHeaderTemplate(template); err == nil {
+			t.Errorf("Expected an error parsing '%s'\n", template)
+		}
+	}
+}
+
+func TestHeaderTemplate(t *testing.T) {
+	entry := &Entry{
+		Time:      time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+		Level:     WarnLvl,
+		File:      "/my/test/file.go",
+		Line:      145,
+		Message:   "message",
+		Fields:    Fields{"key": "value"},
+		Goroutine: 7,
+	}
+	noCaller := *entry
+	noCaller.File = ""
+	tests := []struct {
+		template string
+		entry    Entry
+		expected string
+	}{
+		{DefaultHeaderTemplate, *entry, "2015-07-02T13:28:42 [WARN] /my/test/file.go:145: g7 message key=value"},
+		{"{level} {time} {caller}: {msg}", *entry, "[WARN] 2015-07-02T13:28:42 /my/test/file.go:145: g7 message key=value"},
+		{"{level} {msg} ({caller})", *entry, "[WARN] g7 message (/my/test/file.go:145) key=value"},
+		{"<{level}> {msg}", *entry, "<[WARN]> g7 message key=value"},
+		{DefaultHeaderTemplate, noCaller, "2015-07-02T13:28:42 [WARN] g7 message key=value"},
+	}
+
+	for _, test := range tests {
+		header, err := ParseHeaderTemplate(test.template)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		var buf []byte
+		TextFormatter{Header: header}.Format(&buf, &test.entry)
+		if string(buf) != test.expected {
+			t.Errorf("Expected '%s' from '%s', got '%s' instead\n", test.expected, test.template, buf)
+		}
+	}
+}
+
+func TestSetHeaderTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetClock(func() time.Time { return time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC) })
+	if _, err := log.SetHeaderTemplate("{level} {time} {msg}"); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Info("level first")
+	expected := "[INFO] 2015-07-02T13:28:42 level first\n"
+	if buf.String() != expected {
+		t.Errorf("Expected '%s', got '%s' instead\n", expected, buf.String())
+	}
+
+	if _, err := log.SetHeaderTemplate("{time}"); err == nil {
+		t.Error("Expected an error for a template without {msg}")
+	}
+}
@@ -0,0 +1,20 @@
+package logging
+
+// Hook is called with every Entry a Logger writes, after its Fields are attached and it is redacted,
+// but before it is formatted. Hooks are useful for side effects like counting entries or forwarding
+// them elsewhere. Fire is called from the goroutine that logged, so it should be quick, and it must be
+// safe for concurrent use. Like a Formatter, a Hook must copy the Entry's Message if it keeps it after
+// Fire returns.
+type Hook interface {
+	Fire(e *Entry)
+}
+
+// AddHook adds a Hook that is fired for every Entry the Logger writes. Hooks are fired in the order they
+// were added. Child Loggers created afterwards inherit the Logger's Hooks; Hooks added to a child are not
+// fired for its parent.
+func (l *Logger) AddHook(h Hook) *Logger {
+	hooks := make([]Hook, len(l.hooks), len(l.hooks)+1)
+	copy(hooks, l.hooks)
+	l.hooks = append(hooks, h)
+	return l
+}
@@ -0,0 +1,8 @@
+package logging
+
+// levelCounter is a Hook that counts the entries it sees at each Level.
+type levelCounter map[Level]int
+
+func (c levelCounter) Fire(e *Entry) {
+	c[e.Level]++
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxJournalFieldName is the longest field name journald accepts.
+const maxJournalFieldName = 64
+
+// JournalFormatter renders an Entry in the systemd journal's native protocol, as a series of KEY=value
+// fields, one per line. The message is written as MESSAGE, its Level as the matching syslog PRIORITY, and
+// its caller as CODE_FILE and CODE_LINE. The Logger's Fields are written with their keys upper-cased,
+// so they can be matched with journalctl, like journalctl REQUEST_ID=abc. Fields named like one the
+// formatter writes itself, like "message" or "priority", are written with an F_ prefix, like F_MESSAGE.
+// Values that span several lines are written in the protocol's length-prefixed form.
+//
+// Every field, including the last, ends with a newline. It is usually used through LogToJournal, whose
+// writer drops the line terminator the Logger appends after the fields.
+type JournalFormatter struct {
+	// Identifier is written as SYSLOG_IDENTIFIER, the name journalctl -t matches. It is omitted if empty.
+	Identifier string
+}
+
+// NewJournalFormatter returns a JournalFormatter whose Identifier is the name of the running program.
+func NewJournalFormatter() JournalFormatter {
+	return JournalFormatter{Identifier: filepath.Base(os.Args[0])}
+}
+
+// Format appends e to buf as journal fields.
+func (f JournalFormatter) Format(buf *[]byte, e *Entry) {
+	appendJournalField(buf, "MESSAGE", e.Message)
+	appendJournalField(buf, "PRIORITY", strconv.Itoa(e.Level.syslogSeverity()))
+	if f.Identifier != "" {
+		appendJournalField(buf, "SYSLOG_IDENTIFIER", f.Identifier)
+	}
+	if e.File != "" {
+		appendJournalField(buf, "CODE_FILE", e.File)
+		appendJournalField(buf, "CODE_LINE", strconv.Itoa(e.Line))
+	}
+	if e.Goroutine != 0 {
+		appendJournalField(buf, "GOROUTINE_ID", strconv.FormatUint(e.Goroutine, 10))
+	}
+	fields := e.Fields.flatten()
+	for _, k := range fields.sortedKeys() {
+		name := journalFieldName(k)
+		if journalReserved[name] {
+			name = "F_" + name
+		}
+		appendJournalField(buf, name, fmt.Sprint(fields[k]))
+	}
+}
+
+// journalReserved holds the names of the fields a JournalFormatter writes for every entry.
+var journalReserved = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"GOROUTINE_ID":      true,
+}
+
+// journalFieldsLen returns the length of the complete journal fields at the start of p, leaving out
+// whatever follows the last of them, like the Logger's line terminator.
+func journalFieldsLen(p []byte) int {
+	end := 0
+	for end < len(p) {
+		nl := bytes.IndexByte(p[end:], '\n')
+		if nl <= 0 {
+			break
+		}
+		if bytes.IndexByte(p[end:end+nl], '=') > 0 {
+			end += nl + 1
+			continue
+		}
+		// A length-prefixed field: the name, a newline, the length, the value, and a newline.
+		start := end + nl + 1
+		if len(p)-start < 8 {
+			break
+		}
+		size := binary.LittleEndian.Uint64(p[start : start+8])
+		if size >= uint64(len(p)-start-8) || p[start+8+int(size)] != '\n' {
+			break
+		}
+		end = start + 8 + int(size) + 1
+	}
+	return end
+}
+
+// appendJournalField appends a single field to buf. Values with newlines can't be written as KEY=value,
+// so they are written as the name, a newline, the length of the value as a little-endian 64-bit integer,
+// and the value itself.
+func appendJournalField(buf *[]byte, name, value string) {
+	*buf = append(*buf, name...)
+	if strings.IndexByte(value, '\n') < 0 {
+		*buf = append(*buf, '=')
+		*buf = append(*buf, value...)
+		*buf = append(*buf, '\n')
+		return
+	}
+	*buf = append(*buf, '\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	*buf = append(*buf, size[:]...)
+	*buf = append(*buf, value...)
+	*buf = append(*buf, '\n')
+}
+
+// journalFieldName returns the journal field name for the field key k. The journal only allows
+// upper-case letters, digits, and underscores in field names, which may not start with an underscore or
+// a digit, so the key is upper-cased, other characters are replaced with underscores, and an F is
+// written before names that would otherwise be invalid.
+func journalFieldName(k string) string {
+	name := []byte(strings.ToUpper(k))
+	for i, c := range name {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 || name[0] == '_' || name[0] >= '0' && name[0] <= '9' {
+		name = append([]byte("F"), name...)
+	}
+	if len(name) > maxJournalFieldName {
+		name = name[:maxJournalFieldName]
+	}
+	return string(name)
+}
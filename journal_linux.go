@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"syscall"
+)
+
+// journalSocket is the address of the journal's native protocol socket.
+var journalSocket = "/run/systemd/journal/socket"
+
+// journalWriter sends each entry to the journal as a single datagram.
+type journalWriter struct {
+	conn *net.UnixConn
+	addr *net.UnixAddr
+}
+
+// LogToJournal creates a new Logger that writes to the local systemd journal over its native protocol,
+// using a JournalFormatter, so the message, level, caller, and Fields of every entry can be matched with
+// journalctl. The connection is closed by the Logger's Close method.
+//
+// If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
+// are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
+// the version of your software running, etc.
+func LogToJournal(level Level, sentry string, sentryTags map[string]string) (*Logger, error) {
+	w, err := newJournalWriter()
+	if err != nil {
+		return nil, err
+	}
+	return NewWithOptions(WithLevel(level), WithOutput(w), WithFormatter(NewJournalFormatter()),
+		WithSentry(sentry, sentryTags))
+}
+
+// newJournalWriter opens an unbound datagram socket to send to the journal with.
+func newJournalWriter() (*journalWriter, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journalWriter{conn: conn, addr: &net.UnixAddr{Name: journalSocket, Net: "unixgram"}}, nil
+}
+
+// WritesEntries reports that each Write is sent as a separate datagram. See EntryWriter.
+func (w *journalWriter) WritesEntries() bool {
+	return true
+}
+
+// Write sends p, the fields of an entry, to the journal. Anything after the last field, like the Logger's
+// line terminator, is dropped. Entries too large for a datagram are written to a temporary file, whose
+// descriptor is passed to the journal instead, as the protocol describes.
+func (j *journalWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	p = p[:journalFieldsLen(p)]
+	_, _, err := j.conn.WriteMsgUnix(p, nil, j.addr)
+	if err == nil {
+		return n, nil
+	}
+	if !isMessageTooLarge(err) {
+		return 0, err
+	}
+	f, err := ioutil.TempFile("/dev/shm", "journal.")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	// The journal reads the file through the descriptor, so its name isn't needed.
+	if err := os.Remove(f.Name()); err != nil {
+		return 0, err
+	}
+	if _, err := f.Write(p); err != nil {
+		return 0, err
+	}
+	if _, _, err := j.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), j.addr); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Close closes the socket.
+func (j *journalWriter) Close() error {
+	return j.conn.Close()
+}
+
+// isMessageTooLarge reports whether err means a datagram was too large to send.
+func isMessageTooLarge(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	if !ok {
+		return false
+	}
+	if sysErr, ok := opErr.Err.(*os.SyscallError); ok {
+		return sysErr.Err == syscall.EMSGSIZE || sysErr.Err == syscall.ENOBUFS
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// listenJournal points the journal socket at a new socket in a temporary directory, and returns it.
+func listenJournal(t *testing.T) *net.UnixConn {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	path := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal("Unexpected error:", err)
+	}
+	orig := journalSocket
+	journalSocket = path
+	t.Cleanup(func() {
+		journalSocket = orig
+		conn.Close()
+		os.RemoveAll(dir)
+	})
+	return conn
+}
+
+func TestLogToJournal(t *testing.T) {
+	conn := listenJournal(t)
+	log, err := LogToJournal(DebugLvl, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer log.Close()
+
+	log.Child(Fields{"user": "bob"}).Error("failed")
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	msg := buf[:n]
+	if !bytes.HasPrefix(msg, []byte("MESSAGE=failed\nPRIORITY=3\n")) || !bytes.HasSuffix(msg, []byte("\nUSER=bob\n")) {
+		t.Errorf("Expected journal fields, got %q instead\n", msg)
+	}
+
+	log.SetLineTerminator("\r\n").Info("terminated")
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err = conn.Read(buf)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if msg := buf[:n]; !bytes.HasPrefix(msg, []byte("MESSAGE=terminated\n")) || bytes.IndexByte(msg, '\r') >= 0 {
+		t.Errorf("Expected the line terminator to be dropped, got %q instead\n", msg)
+	}
+}
+
+func TestJournalFlushInterval(t *testing.T) {
+	conn := listenJournal(t)
+	log, err := LogToJournal(InfoLvl, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer log.Close()
+	log.SetFlushInterval(time.Hour)
+	log.Info("first")
+	log.Info("second")
+	buf := make([]byte, 4096)
+	for _, expected := range []string{"MESSAGE=first\n", "MESSAGE=second\n"} {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		if msg := buf[:n]; !bytes.HasPrefix(msg, []byte(expected)) || bytes.Count(msg, []byte("MESSAGE=")) != 1 {
+			t.Errorf("Expected a datagram per entry, got %q instead\n", msg)
+		}
+	}
+}
+
+func TestJournalWriterLargeEntry(t *testing.T) {
+	conn := listenJournal(t)
+	w, err := newJournalWriter()
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer w.Close()
+
+	entry := append([]byte("MESSAGE="), bytes.Repeat([]byte("x"), 1<<20)...)
+	entry = append(entry, '\n')
+	if n, err := w.Write(entry); err != nil || n != len(entry) {
+		t.Fatalf("Expected to write %d bytes, got %d and %v instead\n", len(entry), n, err)
+	}
+
+	oob := make([]byte, syscall.CmsgSpace(4))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, oobn, _, _, err := conn.ReadMsgUnix(nil, oob)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Expected a control message, got %v and %v instead\n", msgs, err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("Expected a file descriptor, got %v and %v instead\n", fds, err)
+	}
+	f := os.NewFile(uintptr(fds[0]), "journal")
+	defer f.Close()
+	got := make([]byte, len(entry)+1)
+	n, _ := f.ReadAt(got, 0)
+	if !bytes.Equal(got[:n], entry) {
+		t.Errorf("Expected the file to hold the %d byte entry, got %d bytes instead\n", len(entry), n)
+	}
+}
@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJournalFormatter(t *testing.T) {
+	e := &Entry{
+		Time:      time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+		Level:     WarnLvl,
+		File:      "/my/test/file.go",
+		Line:      145,
+		Message:   "disk almost full",
+		Fields:    Fields{"request-id": "abc", "_private": 1, "trace": "one\ntwo", "message": "dup"},
+		Goroutine: 7,
+	}
+	var buf []byte
+	JournalFormatter{Identifier: "app"}.Format(&buf, e)
+	expected := "MESSAGE=disk almost full\n" +
+		"PRIORITY=4\n" +
+		"SYSLOG_IDENTIFIER=app\n" +
+		"CODE_FILE=/my/test/file.go\n" +
+		"CODE_LINE=145\n" +
+		"GOROUTINE_ID=7\n" +
+		"F_PRIVATE=1\n" +
+		"F_MESSAGE=dup\n" +
+		"REQUEST_ID=abc\n" +
+		"TRACE\n\x07\x00\x00\x00\x00\x00\x00\x00one\ntwo\n"
+	if string(buf) != expected {
+		t.Errorf("Expected %q, got %q instead\n", expected, buf)
+	}
+	for _, terminator := range []string{"", "\n", "\r\n"} {
+		if n := journalFieldsLen(append(buf, terminator...)); n != len(buf) {
+			t.Errorf("Expected the fields to end at %d with terminator %q, got %d instead\n", len(buf), terminator, n)
+		}
+	}
+}
+
+func TestJournalFieldName(t *testing.T) {
+	names := map[string]string{
+		"user":      "USER",
+		"http.path": "HTTP_PATH",
+		"2fa":       "F2FA",
+		"":          "F",
+		"_SYSTEMD":  "F_SYSTEMD",
+	}
+	for in, out := range names {
+		if got := journalFieldName(in); got != out {
+			t.Errorf("Expected %q to become %q, got %q instead\n", in, out, got)
+		}
+	}
+	long := journalFieldName("k123456789012345678901234567890123456789012345678901234567890123456789")
+	if len(long) != maxJournalFieldName {
+		t.Errorf("Expected long names to be cut to %d bytes, got %d instead\n", maxJournalFieldName, len(long))
+	}
+}
@@ -0,0 +1,209 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// JSONFormatter renders an Entry as a single-line JSON object, like
+//
+//	{"time":"2015-07-02T13:28:42Z","level":"WARN","caller":"/my/test/file.go:145","msg":"message","key":"value"}
+//
+// Fields are written after the standard keys, sorted by key. Numbers and booleans are written as JSON
+// numbers and booleans, nil as null, groups (see WithGroup) as nested objects, and every other value as a
+// string. See JSONEncoder.
+type JSONFormatter struct {
+	// Indent writes each key on its own line, indented by two spaces, for reading during development.
+	// Log aggregators expect one object per line, so it should be left off in production.
+	Indent bool
+	// KeyMap renames the standard keys, to match an ingestion schema. See JSONKeyMap.
+	KeyMap JSONKeyMap
+}
+
+// JSONKeyMap maps the standard keys a JSONFormatter writes, "time", "level", "caller", "goroutine", and
+// "msg", to the names they are written with, like "time" to "@timestamp" for Elastic Common Schema, or
+// "level" to "severity" for Google Cloud Logging. Keys that aren't in the map keep their standard names.
+type JSONKeyMap map[string]string
+
+// key returns the name the standard key is written with.
+func (m JSONKeyMap) key(standard string) string {
+	if name, ok := m[standard]; ok && name != "" {
+		return name
+	}
+	return standard
+}
+
+// Format appends e to buf as a JSON object.
+func (f JSONFormatter) Format(buf *[]byte, e *Entry) {
+	if f.Indent {
+		var compact []byte
+		JSONFormatter{KeyMap: f.KeyMap}.Format(&compact, e)
+		var indented bytes.Buffer
+		json.Indent(&indented, compact, "", "  ")
+		*buf = append(*buf, indented.Bytes()...)
+		return
+	}
+	*buf = append(*buf, '{')
+	appendJSONString(buf, f.KeyMap.key("time"))
+	*buf = append(*buf, ':', '"')
+	*buf = e.Time.AppendFormat(*buf, time.RFC3339Nano)
+	*buf = append(*buf, '"', ',')
+	appendJSONString(buf, f.KeyMap.key("level"))
+	*buf = append(*buf, ':')
+	appendJSONString(buf, string(e.Level))
+	if e.File != "" {
+		*buf = append(*buf, ',')
+		appendJSONString(buf, f.KeyMap.key("caller"))
+		*buf = append(*buf, ':')
+		appendJSONString(buf, e.File+":"+strconv.Itoa(e.Line))
+	}
+	if e.Goroutine != 0 {
+		*buf = append(*buf, ',')
+		appendJSONString(buf, f.KeyMap.key("goroutine"))
+		*buf = append(*buf, ':')
+		*buf = strconv.AppendUint(*buf, e.Goroutine, 10)
+	}
+	*buf = append(*buf, ',')
+	appendJSONString(buf, f.KeyMap.key("msg"))
+	*buf = append(*buf, ':')
+	appendJSONString(buf, e.Message)
+	encodeEntryFields(JSONEncoder{}, buf, e)
+	*buf = append(*buf, '}')
+}
+
+// withJSONFormatter calls fn to modify the Logger's JSONFormatter, and returns the Logger. If the Logger
+// doesn't use a JSONFormatter, it is left unchanged.
+func (l *Logger) withJSONFormatter(fn func(*JSONFormatter)) *Logger {
+	f, ok := l.formatter.(JSONFormatter)
+	if !ok {
+		return l
+	}
+	fn(&f)
+	l.formatter = f
+	return l
+}
+
+// SetJSONIndent controls whether JSON output is indented across several lines, for reading during
+// development. It is off by default, since log aggregators expect one object per line, and has no effect
+// unless the Logger uses a JSONFormatter.
+func (l *Logger) SetJSONIndent(indent bool) *Logger {
+	return l.withJSONFormatter(func(f *JSONFormatter) {
+		f.Indent = indent
+	})
+}
+
+// SetJSONKeyMap renames the standard keys in JSON output, like "time" to "@timestamp", to match an
+// ingestion schema. See JSONKeyMap. It has no effect unless the Logger uses a JSONFormatter.
+func (l *Logger) SetJSONKeyMap(keys JSONKeyMap) *Logger {
+	return l.withJSONFormatter(func(f *JSONFormatter) {
+		f.KeyMap = keys
+	})
+}
+
+// appendJSONString appends s to buf as a quoted JSON string. Invalid UTF-8 is replaced with U+FFFD.
+func appendJSONString(buf *[]byte, s string) {
+	*buf = append(*buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			*buf = append(*buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				*buf = append(*buf, '\\', c)
+			case '\n':
+				*buf = append(*buf, '\\', 'n')
+			case '\r':
+				*buf = append(*buf, '\\', 'r')
+			case '\t':
+				*buf = append(*buf, '\\', 't')
+			default:
+				*buf = append(*buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			*buf = append(*buf, s[start:i]...)
+			*buf = append(*buf, `�`...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	*buf = append(*buf, s[start:]...)
+	*buf = append(*buf, '"')
+}
+
+// appendJSONValue appends v to buf as a JSON number or boolean if it is one, or as a JSON string
+// otherwise. Non-finite floats are written as strings, since JSON has no representation for them.
+func appendJSONValue(buf *[]byte, v interface{}) {
+	EncodeValue(JSONEncoder{}, buf, v)
+}
+
+// JSONEncoder is the Encoder used by the JSONFormatter. It writes each field as a comma and a
+// "key":value pair, leaving the comma out if the field opens an object, and writes groups as nested
+// objects. Numbers and booleans are written as JSON numbers and booleans, nil as null, and other values
+// as strings.
+type JSONEncoder struct{}
+
+// AppendKey appends a comma, unless buf ends by opening an object, key as a JSON string, and a colon.
+func (JSONEncoder) AppendKey(buf *[]byte, key string) {
+	if n := len(*buf); n > 0 && (*buf)[n-1] != '{' {
+		*buf = append(*buf, ',')
+	}
+	appendJSONString(buf, key)
+	*buf = append(*buf, ':')
+}
+
+// AppendGroup appends group to buf as a nested object under key.
+func (enc JSONEncoder) AppendGroup(buf *[]byte, key string, group Fields) {
+	enc.AppendKey(buf, key)
+	*buf = append(*buf, '{')
+	EncodeFields(enc, buf, group)
+	*buf = append(*buf, '}')
+}
+
+func (JSONEncoder) AppendString(buf *[]byte, s string) { appendJSONString(buf, s) }
+func (JSONEncoder) AppendInt(buf *[]byte, i int64)     { *buf = strconv.AppendInt(*buf, i, 10) }
+func (JSONEncoder) AppendUint(buf *[]byte, u uint64)   { *buf = strconv.AppendUint(*buf, u, 10) }
+func (JSONEncoder) AppendBool(buf *[]byte, b bool)     { *buf = strconv.AppendBool(*buf, b) }
+func (JSONEncoder) AppendNil(buf *[]byte)              { *buf = append(*buf, "null"...) }
+
+func (JSONEncoder) AppendFloat(buf *[]byte, f float64, bitSize int) {
+	appendJSONFloat(buf, f, bitSize)
+}
+
+// AppendTime appends t to buf as a string in RFC 3339 format.
+func (JSONEncoder) AppendTime(buf *[]byte, t time.Time) {
+	*buf = append(*buf, '"')
+	*buf = t.AppendFormat(*buf, time.RFC3339Nano)
+	*buf = append(*buf, '"')
+}
+
+// AppendDuration appends d to buf as a string like "1.5s".
+func (JSONEncoder) AppendDuration(buf *[]byte, d time.Duration) {
+	appendJSONString(buf, d.String())
+}
+
+// appendJSONFloat appends f to buf as a JSON number, or as a string if it is NaN or infinite.
+func appendJSONFloat(buf *[]byte, f float64, bitSize int) {
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if s == "NaN" || s == "+Inf" || s == "-Inf" {
+		appendJSONString(buf, s)
+		return
+	}
+	*buf = append(*buf, s...)
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestJSONFormatterIndent(t *testing.T) {
+	var buf []byte
+	JSONFormatter{Indent: true}.Format(&buf, &Entry{
+		Time:    time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+		Level:   InfoLvl,
+		File:    "/my/test/file.go",
+		Line:    145,
+		Message: "indented",
+		Fields:  Fields{"user": "bob"},
+	})
+	expected := "{\n" +
+		"  \"time\": \"2015-07-02T13:28:42Z\",\n" +
+		"  \"level\": \"INFO\",\n" +
+		"  \"caller\": \"/my/test/file.go:145\",\n" +
+		"  \"msg\": \"indented\",\n" +
+		"  \"user\": \"bob\"\n" +
+		"}"
+	if string(buf) != expected {
+		t.Errorf("Expected %q, got %q instead\n", expected, buf)
+	}
+}
+
+func TestSetJSONIndent(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithFormatter(JSONFormatter{}))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetJSONIndent(true).Info("indented")
+	if !bytes.HasPrefix(buf.Bytes(), []byte("{\n  \"time\": ")) || !bytes.HasSuffix(buf.Bytes(), []byte("\n}\n")) {
+		t.Errorf("Expected indented output, got %q instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log.SetJSONIndent(false).Info("compact")
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("Expected compact output on one line, got %q instead\n", buf.String())
+	}
+
+	text, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, ok := text.SetJSONIndent(true).formatter.(TextFormatter); !ok {
+		t.Error("Expected a TextFormatter to be left unchanged")
+	}
+}
+
+func TestJSONFormatterKeyMap(t *testing.T) {
+	var buf []byte
+	f := JSONFormatter{KeyMap: JSONKeyMap{"time": "@timestamp", "msg": "message", "level": "severity", "caller": ""}}
+	f.Format(&buf, &Entry{
+		Time:      time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+		Level:     WarnLvl,
+		File:      "/my/test/file.go",
+		Line:      145,
+		Message:   "renamed",
+		Goroutine: 7,
+	})
+	expected := `{"@timestamp":"2015-07-02T13:28:42Z","severity":"WARN","caller":"/my/test/file.go:145","goroutine":7,"message":"renamed"}`
+	if string(buf) != expected {
+		t.Errorf("Expected %s, got %s instead\n", expected, buf)
+	}
+}
+
+func TestSetJSONKeyMap(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithFormatter(JSONFormatter{}))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetJSONKeyMap(JSONKeyMap{"time": "@timestamp"}).SetJSONIndent(true).Info("renamed")
+	if !bytes.HasPrefix(buf.Bytes(), []byte("{\n  \"@timestamp\": ")) {
+		t.Errorf("Expected the time key to be renamed, got %q instead\n", buf.String())
+	}
+}
@@ -1,13 +1,19 @@
 package logging
 
 import (
+	"bytes"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/context"
@@ -52,15 +58,36 @@ type Level string
 // includes returns true if l "includes" other. l includes other when a message logged at other's Level
 // should be included in a log file that requires at least l severity.
 func (l Level) includes(other Level) bool {
+	return other.severity() >= l.threshold()
+}
+
+// threshold returns the minimum severity a message needs to be included in a log file set to l. Unknown
+// Levels include all output.
+func (l Level) threshold() int32 {
 	switch l {
 	case InfoLvl:
-		return other != DebugLvl
+		return 1
 	case WarnLvl:
-		return other != DebugLvl && other != InfoLvl
+		return 2
 	case ErrorLvl:
-		return other != DebugLvl && other != InfoLvl && other != WarnLvl
+		return 3
 	default:
-		return true
+		return 0
+	}
+}
+
+// severity returns the severity of a message logged at l. Messages logged at unknown Levels are treated
+// as errors, so they are never filtered out.
+func (l Level) severity() int32 {
+	switch l {
+	case DebugLvl:
+		return 0
+	case InfoLvl:
+		return 1
+	case WarnLvl:
+		return 2
+	default:
+		return 3
 	}
 }
 
@@ -82,16 +109,73 @@ func (l Level) asSentryLevel() raven.Severity {
 // Logger is an instance of a log handler, used to write files to the designated output
 // if they meet the specified Level. It is concurrency-safe. Each Logger should have its
 // Close method called when you're done with it.
+//
+// A Logger must always be used as a *Logger, as returned by its constructors; copying the struct
+// would let the copies' state drift apart while they share a lock. Methods that derive a new Logger,
+// like Child, AddTags, and Clone, return a separate *Logger and leave the original untouched. The
+// Set methods modify the Logger in place, and return it for chaining. SetLevel and SetOutput may be
+// called at any time; the other settings should be configured before the Logger is shared between
+// goroutines. A nil *Logger is valid, and discards everything written to it.
 type Logger struct {
 	level           Level
+	threshold       int32
+	levelThreshold  int32
 	out             io.Writer
 	sentry          *raven.Client
+	sentryTags      *sentryTagSet
+	sentryEnv       string
+	sentryRelease   string
 	calldepth       int
-	buf             []byte
 	flock           *sync.Mutex
 	tags            map[string]string
 	meta            []raven.Interface
 	packagePrefixes []string
+	formatter       Formatter
+	utc             bool
+	fields          Fields
+	groups          []string
+	durationUnit    time.Duration
+	errorHandler    func(error)
+	now             func() time.Time
+	goroutineID     bool
+	extra           map[string]interface{}
+	fingerprint     []string
+	sentryPending   *sentryCounter
+	redactor        func(key, value string) string
+	redactPatterns  []*regexp.Regexp
+
+	// sentrySampleRate is the fraction of events sent to Sentry, if sentrySampled is set.
+	sentrySampleRate float64
+	sentrySampled    bool
+
+	sourceLines    int
+	sourceInOutput bool
+	dumpLimit      int
+	callerLevel    Level
+	prefix         string
+	separator      string
+	separatorSet   bool
+
+	maxMessageLength int
+	escapeControl    bool
+	sanitizeUTF8     bool
+
+	continuationPrefix string
+	lineTerminator     string
+	sampler            *sampler
+	filters            []filter
+	hooks              []Hook
+	closer             *closeOnce
+	syncWrites         bool
+	packageLevels      []packageLevel
+	packageLeveled     int32
+	levelCallbacks     []func(old, new Level)
+	discard            int32
+	teed               int32
+	retryAttempts      int
+	retryBackoff       time.Duration
+	fallback           io.Writer
+	fallbackWrites     *uint64
 }
 
 // LogToFile creates a new Logger that writes to a file specified by path. If the file doesn't exist, it
@@ -100,10 +184,10 @@ type Logger struct {
 // If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
 // are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
 // the version of your software running, etc.
-func LogToFile(level Level, path string, sentry string, sentryTags map[string]string) (Logger, error) {
+func LogToFile(level Level, path string, sentry string, sentryTags map[string]string) (*Logger, error) {
 	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
 	if err != nil {
-		return Logger{}, err
+		return nil, err
 	}
 	return New(level, f, sentry, sentryTags)
 }
@@ -113,36 +197,101 @@ func LogToFile(level Level, path string, sentry string, sentryTags map[string]st
 // If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
 // are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
 // the version of your software running, etc.
-func LogToStdout(level Level, sentry string, sentryTags map[string]string) (Logger, error) {
+func LogToStdout(level Level, sentry string, sentryTags map[string]string) (*Logger, error) {
 	return New(level, os.Stdout, sentry, sentryTags)
 }
 
+// ErrNilWriter is returned when a Logger is created with a nil io.Writer. Use Discard for a Logger whose
+// output is thrown away on purpose.
+var ErrNilWriter = errors.New("logging output must not be nil; use Discard to throw output away")
+
+// Discard is an io.Writer that throws away everything written to it, for Loggers whose output isn't
+// wanted. Unlike ioutil.Discard, a Logger that writes to Discard doesn't format its entries at all.
+var Discard io.Writer = discard{}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// NewWithContext creates a new Logger configured by the Options passed, like NewWithOptions, that is
+// closed when ctx is done. This suits short-lived Loggers, like one that writes a file for a single
+// request, whose Close could otherwise be forgotten. Once ctx is done, writing to the Logger, or to
+// any Logger derived from it, does nothing. If the Logger is closed first, it stops watching ctx.
+func NewWithContext(ctx context.Context, opts ...Option) (*Logger, error) {
+	l, err := NewWithOptions(opts...)
+	if err != nil {
+		return l, err
+	}
+	closed := l.closer.closedChan()
+	go func() {
+		select {
+		case <-ctx.Done():
+			l.Close()
+		case <-closed:
+		}
+	}()
+	return l, nil
+}
+
+// NewDiscard creates a new Logger at level that throws its output away. It is fully functional, so it can
+// be passed to code that needs a Logger, but entries written to it are dropped before they are formatted,
+// unless it has Hooks. It is equivalent to calling New with Discard, which can't fail.
+func NewDiscard(level Level) *Logger {
+	l, _ := NewWithOptions(WithLevel(level), WithOutput(Discard))
+	return l
+}
+
 // New creates a new Logger that writes to the io.Writer specified. If the io.Writer is an io.WriteCloser,
-// it will be automatically closed when the Logger's Close method is called.
+// it will be automatically closed when the Logger's Close method is called. If the io.Writer is nil, New
+// returns ErrNilWriter.
 //
 // If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
 // are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
 // the version of your software running, etc.
-func New(level Level, out io.Writer, sentry string, sentryTags map[string]string) (Logger, error) {
-	var sentryClient *raven.Client
-	var err error
-	if sentry != "" {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-		httpClient := &http.Client{Transport: tr}
-		sentryClient, err = raven.NewClient(sentry, sentryTags)
-		if sentryClient != nil {
-			sentryClient.Transport = &raven.HTTPTransport{Http: *httpClient}
+func New(level Level, out io.Writer, sentry string, sentryTags map[string]string) (*Logger, error) {
+	return NewWithOptions(WithLevel(level), WithOutput(out), WithSentry(sentry, sentryTags))
+}
+
+// NewWithOptions creates a new Logger configured by the Options passed. Options are applied in order, and
+// the first one to fail stops construction; the Logger built up to that point is returned alongside the
+// error. Unless overridden, the Logger writes to stderr at InfoLvl using a TextFormatter.
+func NewWithOptions(opts ...Option) (*Logger, error) {
+	l := &Logger{
+		level:          InfoLvl,
+		threshold:      InfoLvl.threshold(),
+		levelThreshold: InfoLvl.threshold(),
+		out:            os.Stderr,
+		formatter:      TextFormatter{},
+		now:            time.Now,
+		flock:          new(sync.Mutex),
+		tags:           map[string]string{},
+
+		sentryPending:  newSentryCounter(),
+		lineTerminator: "\n",
+		closer:         new(closeOnce),
+	}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return l, err
 		}
 	}
-	return Logger{
-		level:  level,
-		out:    out,
-		sentry: sentryClient,
-		flock:  new(sync.Mutex),
-		tags:   map[string]string{},
-	}, err
+	return l, nil
+}
+
+// newSentryClient returns a raven client for dsn. It has no tags of its own; the Logger sends its tags
+// with each event. See sentryTagSet.
+func newSentryClient(dsn string) (*raven.Client, error) {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	httpClient := &http.Client{Transport: tr}
+	sentryClient, err := raven.NewClient(dsn, nil)
+	if sentryClient != nil {
+		sentryClient.Transport = &raven.HTTPTransport{Http: *httpClient}
+	}
+	return sentryClient, err
 }
 
 // LogFromContext returns a Logger that is ready to use from the Context provided. In a case where a Logger
@@ -151,7 +300,7 @@ func New(level Level, out io.Writer, sentry string, sentryTags map[string]string
 // Logger configuration errors; in production, SaveToContext should always be used before trying to retrieve
 // the Logger wtih LogFromContext. Normally, SaveToContext should be called as part of application startup
 // when the Logger is instantiated.
-func LogFromContext(c context.Context) Logger {
+func LogFromContext(c context.Context) *Logger {
 	ctxVal := c.Value(contextKey)
 	if ctxVal == nil {
 		logger, err := New(InfoLvl, os.Stderr, "", nil)
@@ -160,7 +309,7 @@ func LogFromContext(c context.Context) Logger {
 		}
 		return logger
 	}
-	logger, ok := ctxVal.(Logger)
+	logger, ok := ctxVal.(*Logger)
 	if !ok {
 		logger, err := New(InfoLvl, os.Stderr, "", nil)
 		if err != nil {
@@ -174,13 +323,21 @@ func LogFromContext(c context.Context) Logger {
 // SaveToContext adds a Logger to the supplied Context, returning the new Context that contains the Logger.
 // SaveToContext should generally be called during application startup, when the Logger is instantiated. Once
 // a Logger is stored with SaveToContext, it can be retrieved using LogFromContext.
-func SaveToContext(l Logger, base context.Context) context.Context {
+func SaveToContext(l *Logger, base context.Context) context.Context {
 	return context.WithValue(base, contextKey, l)
 }
 
-func (l Logger) makeCopy() Logger {
-	newLogger := l
-	newLogger.buf = nil
+// shallowCopy returns a copy of the Logger that shares its maps and slices. The copy is made under the
+// Logger's lock, so it doesn't race with SetLevel or SetOutput.
+func (l *Logger) shallowCopy() *Logger {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	newLogger := *l
+	return &newLogger
+}
+
+func (l *Logger) makeCopy() *Logger {
+	newLogger := l.shallowCopy()
 	newLogger.tags = map[string]string{}
 	newLogger.meta = nil
 	if l.meta != nil {
@@ -192,13 +349,28 @@ func (l Logger) makeCopy() Logger {
 	for k, v := range l.tags {
 		newLogger.tags[k] = v
 	}
+	if l.extra != nil {
+		newLogger.extra = make(map[string]interface{}, len(l.extra))
+		for k, v := range l.extra {
+			newLogger.extra[k] = v
+		}
+	}
+	return newLogger
+}
+
+// Clone returns an independent copy of the Logger. The copy shares the Logger's output and Sentry client,
+// but has its own lock, so its settings (like its Level) can be changed without affecting the
+// original.
+func (l *Logger) Clone() *Logger {
+	newLogger := l.makeCopy()
+	newLogger.flock = new(sync.Mutex)
 	return newLogger
 }
 
 // AddTags copies the Logger, adds the specified Sentry tags to the Logger, and returns the
 // modified copy. It is meant to be used to add tags to a specific call on the logger that
 // are unique to that log message. The tags are unused if Sentry is not configured on the Logger.
-func (l Logger) AddTags(tags map[string]string) Logger {
+func (l *Logger) AddTags(tags map[string]string) *Logger {
 	newLogger := l.makeCopy()
 	for k, v := range tags {
 		newLogger.tags[k] = v
@@ -210,36 +382,319 @@ func (l Logger) AddTags(tags map[string]string) Logger {
 // from the raven package) to the Logger, and returns the modified copy. It is meant to be used to
 // add extra information to a Sentry message that it doesn't make sense to pass as an argument to the
 // Warnf/Errorf call. The data is unused if Sentry is not configured on the logger.
-func (l Logger) AddMeta(meta ...raven.Interface) Logger {
+//
+// Requests, as *raven.Http, are redacted like a request passed as an argument; see SetRedactPatterns.
+// Other metadata is sent as is, so it must not hold secrets.
+func (l *Logger) AddMeta(meta ...raven.Interface) *Logger {
 	newLogger := l.makeCopy()
 	newLogger.meta = append(newLogger.meta, meta...)
 	return newLogger
 }
 
+// Flush writes any buffered output to the underlying io.Writer, if the io.Writer the Logger was created
+// with has a Flush method (like a *bufio.Writer). For any other io.Writer, Flush does nothing and
+// returns nil.
+func (l *Logger) Flush() error {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	flusher, ok := l.out.(interface {
+		Flush() error
+	})
+	if !ok {
+		return nil
+	}
+	return flusher.Flush()
+}
+
 // Close signifies that a Logger will no longer be used, and the resources allocated to it can be freed.
-// Once the Close method is called, you should not write any more logs using that Logger. Create a new one
-// instead.
-func (l Logger) Close() {
-	l.sentry.Close()
-	if closer, ok := l.out.(io.Closer); ok {
-		closer.Close()
+// Any buffered output is flushed first. Once the Close method is called, you should not write any more
+// logs using that Logger. Create a new one instead. Close waits as long as it takes for buffered output
+// and pending Sentry events to be delivered; use CloseContext to bound the wait.
+//
+// Close only closes the output and Sentry client once, even if it is called again, or called on a Logger
+// derived from this one, which shares them. It returns any errors from flushing and closing the output;
+// later calls return nil. Once a Logger is closed, writing to it does nothing.
+func (l *Logger) Close() error {
+	return l.CloseContext(context.Background())
+}
+
+// CloseContext is like Close, but gives up waiting for buffered output to be flushed and pending Sentry
+// events to be delivered once ctx is done. In that case it returns ctx's error, and leaves the output and
+// Sentry client open, since they are still in use; whatever had not been delivered is abandoned, and
+// Close can be called again to retry.
+func (l *Logger) CloseContext(ctx context.Context) error {
+	flushed := make(chan error, 1)
+	go func() {
+		flushed <- l.Flush()
+	}()
+	var flushErr error
+	select {
+	case flushErr = <-flushed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-l.sentryDone():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if !l.closer.close() {
+		return nil
 	}
+	if l.sentry != nil {
+		l.sentry.Close()
+		if closer, ok := l.sentry.Transport.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+	l.flock.Lock()
+	out := l.out
+	l.flock.Unlock()
+	var closeErr error
+	if closer, ok := out.(io.Closer); ok {
+		closeErr = closer.Close()
+	}
+	return combineErrors(flushErr, closeErr)
+}
+
+// closeOnce records whether a Logger's resources have been closed. It is shared by every Logger that
+// shares those resources.
+type closeOnce struct {
+	closed int32
+
+	mu   sync.Mutex
+	done chan struct{}
+}
+
+// close marks the resources as closed, and reports whether the caller should close them; only the first
+// call returns true.
+func (c *closeOnce) close() bool {
+	if c == nil {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return false
+	}
+	if c.done != nil {
+		close(c.done)
+	}
+	return true
+}
+
+// closedChan returns a channel that is closed once the resources have been closed.
+func (c *closeOnce) closedChan() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done == nil {
+		c.done = make(chan struct{})
+		if atomic.LoadInt32(&c.closed) == 1 {
+			close(c.done)
+		}
+	}
+	return c.done
+}
+
+// isClosed reports whether the resources have been closed.
+func (c *closeOnce) isClosed() bool {
+	return c != nil && atomic.LoadInt32(&c.closed) == 1
+}
+
+// combineErrors returns nil if every error in errs is nil, the only non-nil error if there is one, or an
+// error holding all of their messages otherwise.
+func combineErrors(errs ...error) error {
+	var msgs []string
+	var last error
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+			last = err
+		}
+	}
+	if len(msgs) > 1 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return last
 }
 
 // GetLevel returns the Level assigned to the Logger.
-func (l Logger) GetLevel() Level {
+func (l *Logger) GetLevel() Level {
+	l.flock.Lock()
+	defer l.flock.Unlock()
 	return l.level
 }
 
-// SetLevel updates the Level assigned to the Logger.
-func (l Logger) SetLevel(lvl Level) Logger {
+// SetLevel updates the Level assigned to the Logger. It is safe to call while other goroutines are
+// logging. Any functions registered with OnLevelChange are called once the Level is updated.
+func (l *Logger) SetLevel(lvl Level) *Logger {
+	l.flock.Lock()
+	old := l.level
+	l.setLevel(lvl)
+	callbacks := l.levelCallbacks
+	l.flock.Unlock()
+	for _, fn := range callbacks {
+		fn(old, lvl)
+	}
+	return l
+}
+
+// OnLevelChange registers fn to be called whenever SetLevel is called on the Logger, with the Level
+// before and after the call, so other parts of a program can follow the Logger's verbosity. They may be
+// the same Level. Functions are called in the order they were registered, from the goroutine that called
+// SetLevel, after the Logger's lock is released, so they may use the Logger. Child Loggers created
+// afterwards inherit the Logger's functions; functions registered on a child are not called for its
+// parent.
+func (l *Logger) OnLevelChange(fn func(old, new Level)) *Logger {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	callbacks := make([]func(old, new Level), len(l.levelCallbacks), len(l.levelCallbacks)+1)
+	copy(callbacks, l.levelCallbacks)
+	l.levelCallbacks = append(callbacks, fn)
+	return l
+}
+
+func (l *Logger) setLevel(lvl Level) {
 	l.level = lvl
+	atomic.StoreInt32(&l.levelThreshold, lvl.threshold())
+	atomic.StoreInt32(&l.threshold, l.lowestThreshold(lvl))
+}
+
+// enabled returns true if the Logger should write messages logged at lvl. The check doesn't take the
+// Logger's lock, so suppressed messages are cheap. Nil and zero-value Loggers are never enabled.
+func (l *Logger) enabled(lvl Level) bool {
+	return l != nil && l.flock != nil && lvl.severity() >= atomic.LoadInt32(&l.threshold)
+}
+
+// SetOutput redirects the logs from the Logger to a new destination. It is safe to call while other
+// goroutines are logging.
+func (l *Logger) SetOutput(out io.Writer) *Logger {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	l.setOutput(out)
 	return l
 }
 
-// SetOutput redirects the logs from the Logger to a new destination.
-func (l Logger) SetOutput(out io.Writer) Logger {
+// setOutput sets l.out, and records whether it is Discard so output can skip formatting. The caller must
+// hold l.flock, or be the only user of l.
+func (l *Logger) setOutput(out io.Writer) {
 	l.out = out
+	var discard, teed int32
+	if out == Discard {
+		discard = 1
+	}
+	if _, ok := out.(sinks); ok {
+		teed = 1
+	}
+	atomic.StoreInt32(&l.discard, discard)
+	atomic.StoreInt32(&l.teed, teed)
+}
+
+// GetOutput returns the io.Writer the Logger writes to. It is safe to call while other goroutines are
+// logging or calling SetOutput.
+func (l *Logger) GetOutput() io.Writer {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	return l.out
+}
+
+// SetLineTerminator sets the string written after every entry, which defaults to "\n". Use "\r\n" for
+// Windows tooling, "\x00" for null-delimited pipelines, or "" when the output frames entries itself.
+func (l *Logger) SetLineTerminator(terminator string) *Logger {
+	l.lineTerminator = terminator
+	return l
+}
+
+// SetSync controls whether the output is synced to stable storage after every entry is written, so the
+// entry survives a crash or power loss once the logging call returns. It only has an effect when the
+// output has a Sync method, like an *os.File. Syncing every entry is very slow, so it is off by default,
+// and should only be turned on for logs that must be durable, like audit logs.
+func (l *Logger) SetSync(sync bool) *Logger {
+	l.syncWrites = sync
+	return l
+}
+
+// SetWriteRetry makes the Logger retry writes to its output that fail with a temporary error, like
+// EAGAIN from a busy pipe or socket, up to attempts more times, waiting backoff before the first retry
+// and twice as long before each one after it. Errors that aren't temporary, and the last error once the
+// retries run out, go to the error handler as usual. Other goroutines can't log while a write is being
+// retried, so keep the backoff short. Retries are off by default.
+func (l *Logger) SetWriteRetry(attempts int, backoff time.Duration) *Logger {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	l.retryAttempts = attempts
+	l.retryBackoff = backoff
+	return l
+}
+
+// SetFallback sets an io.Writer, like stderr or a local file, that entries are written to when writing
+// them to the Logger's output fails, so they are kept instead of lost while a network sink is down. An
+// entry is only written to the fallback after any retries set by SetWriteRetry are used up. Entries that
+// reach the fallback aren't reported to the error handler; FallbackWrites counts them instead. If the
+// fallback fails too, the error from the output is reported. Passing nil removes the fallback.
+func (l *Logger) SetFallback(fallback io.Writer) *Logger {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	l.fallback = fallback
+	l.fallbackWrites = new(uint64)
+	return l
+}
+
+// FallbackWrites returns the number of entries written to the Logger's fallback since SetFallback was
+// called, including those written by Loggers derived from it.
+func (l *Logger) FallbackWrites() uint64 {
+	l.flock.Lock()
+	counter := l.fallbackWrites
+	l.flock.Unlock()
+	if counter == nil {
+		return 0
+	}
+	return atomic.LoadUint64(counter)
+}
+
+// isTemporary reports whether err, or an error it wraps, says it is temporary.
+func isTemporary(err error) bool {
+	for err != nil {
+		if t, ok := err.(interface {
+			Temporary() bool
+		}); ok && t.Temporary() {
+			return true
+		}
+		u, ok := err.(interface {
+			Unwrap() error
+		})
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+// SetErrorHandler sets the function called when the Logger fails to write to its output. By default,
+// these errors are written to stderr, prefixed with the current time. Passing nil restores the default.
+func (l *Logger) SetErrorHandler(handler func(error)) *Logger {
+	l.errorHandler = handler
+	return l
+}
+
+// SetClock replaces the function the Logger uses to get the current time, which defaults to time.Now. It
+// is meant for tests that need to assert on timestamps. Passing nil restores the default.
+func (l *Logger) SetClock(now func() time.Time) *Logger {
+	l.now = now
+	return l
+}
+
+// SetIncludeGoroutineID controls whether each entry records the ID of the goroutine that logged it, which
+// the TextFormatter writes as "g<ID>" between the header and the message. This is useful for correlating
+// interleaved lines when debugging concurrency issues, but it is expensive: the ID is parsed from the
+// output of runtime.Stack on every call, and that format is not guaranteed to stay the same across Go
+// versions. It is off by default.
+func (l *Logger) SetIncludeGoroutineID(include bool) *Logger {
+	l.goroutineID = include
 	return l
 }
 
@@ -247,14 +702,14 @@ func (l Logger) SetOutput(out io.Writer) Logger {
 // how many calls up the stack the Logger should look when deciding what file/line combo created the log
 // statement. This defaults to 0, which is accurate if you're just calling the Logger directly. For every
 // level of indirection, add 1.
-func (l Logger) SetCallDepth(depth int) Logger {
+func (l *Logger) SetCallDepth(depth int) *Logger {
 	l.calldepth = depth
 	return l
 }
 
 // SetSentry updates the DSN and tags that will be used to send errors to Sentry.
-func (l Logger) SetSentry(dsn string, tags map[string]string) (Logger, error) {
-	sentryClient, err := raven.NewClient(dsn, tags)
+func (l *Logger) SetSentry(dsn string, tags map[string]string) (*Logger, error) {
+	sentryClient, err := raven.NewClient(dsn, nil)
 	if err != nil {
 		return l, err
 	}
@@ -262,74 +717,51 @@ func (l Logger) SetSentry(dsn string, tags map[string]string) (Logger, error) {
 		l.sentry.Close()
 	}
 	l.sentry = sentryClient
+	l.sentryTags = newSentryTagSet(tags)
+	l.configureSentry()
 	return l, nil
 }
 
+// SetSentryTransport replaces the transport the Logger's Sentry client uses to deliver events. It is
+// mostly useful in tests, to capture events instead of sending them; see the logtest package. It does
+// nothing if Sentry has not been configured.
+func (l *Logger) SetSentryTransport(transport raven.Transport) *Logger {
+	if l.sentry == nil {
+		return l
+	}
+	l.sentry.Transport = transport
+	return l
+}
+
 // SetPackagePrefixes sets the package prefixes that will be used to determine
 // if a package should be considered "in app" in sentry. Stacktraces will use
 // this information to flag lines of stacktraces that are from the application,
 // as opposed to being from a third party library or from the standard library.
-func (l Logger) SetPackagePrefixes(prefixes []string) Logger {
+func (l *Logger) SetPackagePrefixes(prefixes []string) *Logger {
 	l.packagePrefixes = prefixes
 	return l
 }
 
 // SetRelease sets the release of the application (usually a git SHA1) that
-// recorded the log. This is only used to tag the logs sent to Sentry, so we
-// know which releases produced the errors.
-func (l Logger) SetRelease(release string) Logger {
-	if l.sentry == nil {
-		return l
-	}
-	l.sentry.SetRelease(release)
-	return l
-}
-
-// Debugf writes a log entry with the Level of DebugLvl, interpolating the format
-// string with the arguments passed. See fmt.Sprintf for information on variable
-// placeholders in the format string.
-func (l Logger) Debugf(format string, msg ...interface{}) {
-	if l.out == nil {
-		return
-	}
-	if !l.level.includes(DebugLvl) {
-		return
-	}
-	l.logf(format, DebugLvl, msg...)
-}
-
-// Debug writes a log entry with the Level of DebugLvl, joining each argument passed
-// with a space.
-func (l Logger) Debug(msg ...interface{}) {
-	if l.out == nil {
-		return
-	}
-	if !l.level.includes(DebugLvl) {
-		return
-	}
-	l.log(DebugLvl, msg...)
+// recorded the log. It is the same as SetSentryRelease.
+func (l *Logger) SetRelease(release string) *Logger {
+	return l.SetSentryRelease(release)
 }
 
 // Infof writes a log entry with the Level of InfoLvl, interpolating the format
 // string with the arguments passed. See fmt.Sprintf for information on variable
 // placeholders in the format string.
-func (l Logger) Infof(format string, msg ...interface{}) {
-	if l.out == nil {
-		return
-	}
-	if !l.level.includes(InfoLvl) {
+func (l *Logger) Infof(format string, msg ...interface{}) {
+	if !l.enabled(InfoLvl) {
 		return
 	}
 	l.logf(format, InfoLvl, msg...)
 }
 
 // Info writes a log entry with the Level of InfoLvl, joining each argument passed
-// with a space.
-func (l Logger) Info(msg ...interface{}) {
-	if l.out == nil {
-		return
-	}
-	if !l.level.includes(InfoLvl) {
+// with a space, as fmt.Sprintln does. See SetSeparator.
+func (l *Logger) Info(msg ...interface{}) {
+	if !l.enabled(InfoLvl) {
 		return
 	}
 	l.log(InfoLvl, msg...)
@@ -340,32 +772,26 @@ func (l Logger) Info(msg ...interface{}) {
 // placeholders in the format string.
 //
 // Any message logged with Warnf will automatically be sent to Sentry, if Sentry
-// has been configured.
-func (l Logger) Warnf(format string, msg ...interface{}) {
-	if l.out == nil {
-		return
-	}
-	if !l.level.includes(WarnLvl) {
+// has been configured. The event is sent in the background; see FlushSentry.
+func (l *Logger) Warnf(format string, msg ...interface{}) {
+	if !l.enabled(WarnLvl) {
 		return
 	}
 	l.logf(format, WarnLvl, msg...)
-	l.toSentry(format, msg, WarnLvl)
+	l.toSentry(format, msg, WarnLvl, nil)
 }
 
 // Warn writes a log entry with the Level of WarnLvl, joining each argument passed
-// with a space.
+// with a space, as fmt.Sprintln does. See SetSeparator.
 //
 // Any message logged with Warn will automatically be sent to Sentry, if Sentry
-// has been configured.
-func (l Logger) Warn(msg ...interface{}) {
-	if l.out == nil {
-		return
-	}
-	if !l.level.includes(WarnLvl) {
+// has been configured. The event is sent in the background; see FlushSentry.
+func (l *Logger) Warn(msg ...interface{}) {
+	if !l.enabled(WarnLvl) {
 		return
 	}
 	l.log(WarnLvl, msg...)
-	l.toSentry(fmt.Sprintln(msg...), []interface{}{}, WarnLvl)
+	l.toSentry(l.joinln(msg...), []interface{}{}, WarnLvl, nil)
 }
 
 // Errorf writes a log entry with the Level of ErrorLvl, interpolating the format
@@ -373,48 +799,62 @@ func (l Logger) Warn(msg ...interface{}) {
 // placeholders in the format string.
 //
 // Any message logged with Errorf will automatically be sent to Sentry, if Sentry
-// has been configured.
-func (l Logger) Errorf(format string, msg ...interface{}) {
-	if l.out == nil {
-		return
-	}
-	if !l.level.includes(ErrorLvl) {
+// has been configured. The event is sent in the background; see FlushSentry.
+func (l *Logger) Errorf(format string, msg ...interface{}) {
+	if !l.enabled(ErrorLvl) {
 		return
 	}
 	l.logf(format, ErrorLvl, msg...)
-	l.toSentry(format, msg, ErrorLvl)
+	l.toSentry(format, msg, ErrorLvl, nil)
 }
 
 // Error writes a log entry with the Level of ErrorLvl, joining each argument passed
-// with a space.
+// with a space, as fmt.Sprintln does. See SetSeparator.
 //
 // Any message logged with Error will automatically be sent to Sentry, if Sentry
-// has been configured.
-func (l Logger) Error(msg ...interface{}) {
-	if l.out == nil {
-		return
-	}
-	if !l.level.includes(ErrorLvl) {
+// has been configured. The event is sent in the background; see FlushSentry.
+func (l *Logger) Error(msg ...interface{}) {
+	if !l.enabled(ErrorLvl) {
 		return
 	}
 	l.log(ErrorLvl, msg...)
-	l.toSentry(fmt.Sprintln(msg...), []interface{}{}, ErrorLvl)
+	l.toSentry(l.joinln(msg...), []interface{}{}, ErrorLvl, nil)
 }
 
-func (l Logger) log(lvl Level, msg ...interface{}) {
-	err := l.output(l.calldepth+3, fmt.Sprintln(msg...), lvl)
+func (l *Logger) log(lvl Level, msg ...interface{}) {
+	err := l.output(l.calldepth+3, l.join(msg...), lvl)
 	if err != nil {
-		os.Stderr.Write([]byte(time.Now().String() + " " + err.Error()))
+		l.handleError(err)
 	}
 }
 
-func (l Logger) logf(format string, lvl Level, msg ...interface{}) {
+func (l *Logger) logf(format string, lvl Level, msg ...interface{}) {
 	err := l.output(l.calldepth+3, fmt.Sprintf(format, msg...), lvl)
 	if err != nil {
-		os.Stderr.Write([]byte(time.Now().String() + " " + err.Error()))
+		l.handleError(err)
 	}
 }
 
+// sprintln is fmt.Sprintln, with a fast path for the common case of a single string that avoids
+// formatting and allocating. The trailing newline Sprintln would add is dropped by output anyway.
+func sprintln(msg ...interface{}) string {
+	if len(msg) == 1 {
+		if s, ok := msg[0].(string); ok && !strings.HasSuffix(s, "\n") {
+			return s
+		}
+	}
+	return fmt.Sprintln(msg...)
+}
+
+// handleError reports an error writing to l.out, using the configured error handler if there is one.
+func (l *Logger) handleError(err error) {
+	if l.errorHandler != nil {
+		l.errorHandler(err)
+		return
+	}
+	os.Stderr.Write([]byte(time.Now().String() + " " + err.Error()))
+}
+
 // Cheap integer to fixed-width decimal ASCII.  Give a negative width to avoid zero-padding.
 // Knows the buffer has capacity.
 //
@@ -437,83 +877,329 @@ func itoa(buf *[]byte, i int, wid int) {
 	*buf = append(*buf, b[bp:]...)
 }
 
-// Prepend our log header to the buffer.
-//
-// Heavily modified form of https://github.com/golang/go/blob/883bc6ed0ea815293fe6309d66f967ea60630e87/src/log/log.go#L80
-func formatHeader(buf *[]byte, now time.Time, file string, line int, level Level) {
-	year, month, day := now.Date()
-	itoa(buf, year, 4)
-	*buf = append(*buf, '-')
-	itoa(buf, int(month), 2)
-	*buf = append(*buf, '-')
-	itoa(buf, day, 2)
-	*buf = append(*buf, 'T')
-	hour, minute, second := now.Clock()
-	itoa(buf, hour, 2)
-	*buf = append(*buf, ':')
-	itoa(buf, minute, 2)
-	*buf = append(*buf, ':')
-	itoa(buf, second, 2)
-
-	*buf = append(*buf, " ["+string(level)+"] "...)
-
-	*buf = append(*buf, file...)
-	*buf = append(*buf, ':')
-	itoa(buf, line, -1)
-	*buf = append(*buf, ": "...)
-}
-
 // Actually write to l.out after gathering caller information
 //
 // Heavily modified version of https://github.com/golang/go/blob/883bc6ed0ea815293fe6309d66f967ea60630e87/src/log/log.go#L130
-func (l Logger) output(calldepth int, s string, lvl Level) error {
-	now := time.Now()
-	_, file, line, ok := runtime.Caller(calldepth)
-	if !ok {
-		file = "???"
-		line = 0
+func (l *Logger) output(calldepth int, s string, lvl Level) error {
+	return l.outputAt(calldepth+1, 0, time.Time{}, s, lvl, nil)
+}
+
+// outputAt is output for entries that may already know when and where they were logged, like those from
+// a slog.Record. If t is zero, the Logger's clock is used. If calldepth is negative, the caller is the
+// call site with program counter pc, or unknown if pc is 0, rather than being looked up on the stack.
+// typed holds the fields attached to this entry alone, by the w methods.
+func (l *Logger) outputAt(calldepth int, pc uintptr, t time.Time, s string, lvl Level, typed []Field) error {
+	if atomic.LoadInt32(&l.discard) == 1 && l.hooks == nil {
+		return nil
+	}
+	if l.filtered(s) {
+		return nil
+	}
+	now := t
+	if now.IsZero() {
+		now = l.clock()
+	}
+	sampled, dropped := l.sampler.sample(lvl, now)
+	if !sampled {
+		return nil
+	}
+	var file string
+	var line int
+	wantCaller := l.callerLevel == "" || l.callerLevel.includes(lvl)
+	packageLeveled := atomic.LoadInt32(&l.packageLeveled) == 1
+	if wantCaller || packageLeveled {
+		var ok bool
+		if calldepth < 0 {
+			file, line, ok = callerAt(pc)
+		} else {
+			file, line, ok = callerOf(calldepth)
+		}
+		if !ok {
+			file = "???"
+			line = 0
+		}
+	}
+	if packageLeveled && !l.packageIncludes(file, lvl) {
+		return nil
+	}
+	if !wantCaller {
+		file, line = "", 0
+	}
+	entry := &Entry{
+		Time:    now,
+		Level:   lvl,
+		File:    file,
+		Line:    line,
+		Message: l.prefix + strings.TrimSuffix(s, "\n"),
+		typed:   typed,
+	}
+	if l.goroutineID {
+		entry.Goroutine = goroutineID()
+	}
+	if lvl == ErrorLvl && l.sourceInOutput {
+		entry.Message += sourceContext(file, line, l.sourceLines)
+	}
+	if dropped > 0 {
+		report := *entry
+		report.Message = l.prefix + "sampled out " + strconv.FormatUint(dropped, 10) + " " + string(lvl) + " messages"
+		if err := l.write(&report); err != nil {
+			return err
+		}
+	}
+	return l.write(entry)
+}
+
+// write formats the Entry, attaching the Logger's Fields, and writes it to l.out. The Entry's typed
+// fields are merged into its Fields unless they can be encoded as they are; see encodesTyped.
+func (l *Logger) write(entry *Entry) error {
+	if l.utc {
+		entry.Time = entry.Time.UTC()
+	}
+	formatter := l.formatter
+	if formatter == nil {
+		formatter = TextFormatter{}
+	}
+	fields := l.fields
+	if len(entry.typed) > 0 && !l.encodesTyped(formatter, entry.typed) {
+		fields = l.mergeTyped(fields, entry.typed)
+		entry.typed = nil
+	}
+	entry.Fields, _ = l.formatTimes(fields)
+	entry.typed = l.formatTyped(entry.typed)
+	l.redact(entry)
+	if l.sanitizeUTF8 {
+		entry.Message = sanitizeUTF8(entry.Message)
 	}
-	l.buf = l.buf[:0]
-	formatHeader(&l.buf, now, file, line, lvl)
-	l.buf = append(l.buf, s...)
-	if len(s) > 0 && s[len(s)-1] != '\n' {
-		l.buf = append(l.buf, '\n')
+	if l.escapeControl {
+		entry.Message = escapeControl(entry.Message)
 	}
+	entry.Message = indentContinuations(entry.Message, l.continuationPrefix)
+	entry.Message = truncate(entry.Message, l.maxMessageLength)
+	for _, h := range l.hooks {
+		h.Fire(entry)
+	}
+	if atomic.LoadInt32(&l.discard) == 1 || l.closer.isClosed() {
+		return nil
+	}
+	if atomic.LoadInt32(&l.teed) == 1 {
+		return l.writeSinks(entry)
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	formatter.Format(buf, entry)
+	*buf = append(*buf, l.lineTerminator...)
+	return l.emit(entry.Level, *buf)
+}
+
+// emit writes p, a formatted entry at lvl, to l.out, falling back to l.fallback if that fails.
+func (l *Logger) emit(lvl Level, p []byte) error {
 	l.flock.Lock()
 	defer l.flock.Unlock()
-	_, err := l.out.Write(l.buf)
+	if l.out == nil {
+		return nil
+	}
+	return l.emitTo(l.out, lvl, p)
+}
+
+// emitTo writes p, a formatted entry at lvl, to out, falling back to l.fallback if that fails. The caller
+// must hold l.flock.
+func (l *Logger) emitTo(out io.Writer, lvl Level, p []byte) error {
+	err := l.writeOut(out, lvl, p)
+	if err != nil && l.fallback != nil {
+		if _, fallbackErr := l.fallback.Write(p); fallbackErr == nil {
+			atomic.AddUint64(l.fallbackWrites, 1)
+			return nil
+		}
+	}
+	if err == nil && l.syncWrites {
+		if syncer, ok := out.(interface {
+			Sync() error
+		}); ok {
+			err = syncer.Sync()
+		}
+	}
 	return err
 }
 
-// Send output to Sentry
-func (l Logger) toSentry(format string, args []interface{}, lvl Level) {
+// writeOut writes p to out, retrying temporary errors as configured by SetWriteRetry. The caller must
+// hold l.flock.
+func (l *Logger) writeOut(out io.Writer, lvl Level, p []byte) error {
+	backoff := l.retryBackoff
+	for attempt := 0; ; attempt++ {
+		var n int
+		var err error
+		if lw, ok := out.(LevelWriter); ok {
+			n, err = lw.WriteLevel(lvl, p)
+		} else {
+			n, err = out.Write(p)
+		}
+		if err == nil || attempt >= l.retryAttempts || !isTemporary(err) {
+			return err
+		}
+		if n > 0 && n < len(p) {
+			p = p[n:]
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// LevelWriter is an io.Writer that also needs to know the Level of what it writes, like a connection to
+// syslog. When a Logger's output is a LevelWriter, it calls WriteLevel instead of Write.
+type LevelWriter interface {
+	io.Writer
+	WriteLevel(level Level, p []byte) (n int, err error)
+}
+
+// EntryWriter is an io.Writer that sends each Write as a separate message, like a datagram, so each Write
+// must hold a single entry. SetFlushInterval leaves EntryWriters unbuffered, since a BatchWriter would
+// join several entries into one message.
+type EntryWriter interface {
+	io.Writer
+	// WritesEntries reports whether each Write is sent as a separate message.
+	WritesEntries() bool
+}
+
+// maxPooledBuffer is the capacity above which buffers are not returned to bufferPool, so one huge entry
+// doesn't pin a huge buffer in memory.
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds the buffers entries are formatted into, so concurrent writers don't allocate a new
+// buffer for every entry.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 512)
+		return &buf
+	},
+}
+
+// getBuffer returns an empty buffer from bufferPool.
+func getBuffer() *[]byte {
+	buf := bufferPool.Get().(*[]byte)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// putBuffer returns buf to bufferPool, unless it has grown too large to keep.
+func putBuffer(buf *[]byte) {
+	if cap(*buf) > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from the first line of its stack trace,
+// which looks like "goroutine 42 [running]:". It returns 0 if the ID can't be parsed.
+func goroutineID() uint64 {
+	var stack [64]byte
+	b := stack[:runtime.Stack(stack[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	var id uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+	return id
+}
+
+// clock returns the current time, according to the Logger's clock.
+func (l *Logger) clock() time.Time {
+	if l.now == nil {
+		return time.Now()
+	}
+	return l.now()
+}
+
+// Send output to Sentry. The fields attached to a single entry, by the w and wf methods, are sent in
+// the event's extra context, along with the Logger's.
+func (l *Logger) toSentry(format string, args []interface{}, lvl Level, fields []Field) {
 	if l.sentry == nil {
 		return
 	}
+	// Package levels may let calls the Logger's own Level excludes get this far, but they only apply to
+	// the output.
+	if lvl.severity() < atomic.LoadInt32(&l.levelThreshold) {
+		return
+	}
+	if l.sentrySampled && sentryRand() >= l.sentrySampleRate {
+		return
+	}
+	// The Sentry event is redacted just like the output, so what is redacted from one doesn't leak
+	// through the other.
 	msg := raven.Message{
-		Message: format,
-		Params:  args,
+		Message: l.redactString(format),
+		Params:  l.redactParams(args),
 	}
-	stack := raven.NewStacktrace(l.calldepth+2, 2, l.packagePrefixes)
+	stack := raven.NewStacktrace(l.calldepth+2, l.sentryContextLines(lvl), l.packagePrefixes)
 	interfaces := []raven.Interface{&msg, stack}
 	for _, arg := range args {
 		if i, ok := l.asSentryInterface(arg); ok {
+			switch i := i.(type) {
+			case *raven.Exception:
+				i.Value = l.redactString(i.Value)
+			case *raven.Http:
+				interfaces = append(interfaces, l.redactHTTP(i))
+				continue
+			}
 			interfaces = append(interfaces, i)
 		}
 	}
-	if l.meta != nil && len(l.meta) > 0 {
-		interfaces = append(interfaces, l.meta...)
+	for _, m := range l.meta {
+		if h, ok := m.(*raven.Http); ok {
+			m = l.redactHTTP(h)
+		}
+		interfaces = append(interfaces, m)
 	}
-	packet := raven.NewPacket(fmt.Sprintf(format, args...), interfaces...)
+	packet := raven.NewPacket(l.redactString(fmt.Sprintf(format, args...)), interfaces...)
 	packet.Level = lvl.asSentryLevel()
-	_, ch := l.sentry.Capture(packet, l.tags)
-	err := <-ch
+	extra := Fields(l.extra)
+	if len(fields) > 0 {
+		extra = make(Fields, len(l.extra)+len(fields))
+		for k, v := range l.extra {
+			extra[k] = v
+		}
+		for _, f := range fields {
+			if f.kind != skipField {
+				extra[f.Key] = f.Value()
+			}
+		}
+	}
+	if len(extra) > 0 {
+		if packet.Extra == nil {
+			packet.Extra = make(map[string]interface{}, len(extra))
+		}
+		if l.redacting() {
+			extra = l.redactFields(extra)
+		}
+		for k, v := range extra {
+			packet.Extra[k] = v
+		}
+	}
+	if len(l.fingerprint) > 0 {
+		packet.Fingerprint = l.fingerprint
+	}
+	_, ch := l.sentry.Capture(packet, l.sentryTags.with(l.tags))
+	if l.sentryPending == nil {
+		l.sentryResult(<-ch)
+		return
+	}
+	l.sentryPending.add()
+	go func() {
+		defer l.sentryPending.finish()
+		l.sentryResult(<-ch)
+	}()
+}
+
+// sentryResult logs the failure to deliver an event to Sentry, if there was one.
+func (l *Logger) sentryResult(err error) {
 	if err != nil {
 		l.output(1, err.Error(), ErrorLvl)
 	}
 }
 
-func (l Logger) asSentryInterface(arg interface{}) (raven.Interface, bool) {
+func (l *Logger) asSentryInterface(arg interface{}) (raven.Interface, bool) {
 	switch arg.(type) {
 	case error:
 		stack := raven.NewStacktrace(l.calldepth+3, 2, l.packagePrefixes)
@@ -1,13 +1,22 @@
 package logging
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"reflect"
+	"runtime"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
+	"golang.org/x/net/context"
+
 	"github.com/DramaFever/raven-go"
 )
 
@@ -121,10 +130,11 @@ func TestItoa(t *testing.T) {
 
 func TestFormatHeader(t *testing.T) {
 	type header struct {
-		now   time.Time
-		file  string
-		line  int
-		level Level
+		now       time.Time
+		file      string
+		line      int
+		level     Level
+		formatter TextFormatter
 	}
 	headers := map[string]header{
 		"2015-07-02T13:28:42 [WARN] /my/test/file.go:145: ": {
@@ -133,10 +143,122 @@ func TestFormatHeader(t *testing.T) {
 			line:  145,
 			level: WarnLvl,
 		},
+		"2015-07-02T13:28:42 [INFO ] /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     InfoLvl,
+			formatter: TextFormatter{AlignLevels: true},
+		},
+		"2015-07-02T13:28:42 [ERROR] /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     ErrorLvl,
+			formatter: TextFormatter{AlignLevels: true},
+		},
+		"2015-07-02T13:28:42 D /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     DebugLvl,
+			formatter: TextFormatter{AlignLevels: true, CompactLevel: true},
+		},
+		"2015-07-02T13:28:42 E /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     ErrorLvl,
+			formatter: TextFormatter{CompactLevel: true},
+		},
+		"1435843722 [WARN] /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 123456789, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     WarnLvl,
+			formatter: TextFormatter{Epoch: EpochSeconds},
+		},
+		"1435843722123 [WARN] /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 123456789, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     WarnLvl,
+			formatter: TextFormatter{Epoch: EpochMillis},
+		},
+		"1435843722123456 [WARN] /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 123456789, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     WarnLvl,
+			formatter: TextFormatter{Epoch: EpochMicros},
+		},
+		"1435843722123456789 [WARN] /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 123456789, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     WarnLvl,
+			formatter: TextFormatter{Epoch: EpochNanos},
+		},
+		"2015-07-02T13:28:42.123 [WARN] /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 123456789, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     WarnLvl,
+			formatter: TextFormatter{TimePrecision: 3},
+		},
+		"2015-07-02T13:28:42.000000001 [WARN] /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 1, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     WarnLvl,
+			formatter: TextFormatter{TimePrecision: 12},
+		},
+		"2015-07-02T13:28:42 [WARN] test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     WarnLvl,
+			formatter: TextFormatter{TrimPrefixes: []string{"/other/", "/my/", "/"}},
+		},
+		"2015-07-02T13:28:42 \x1b[33m[WARN]\x1b[0m /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     WarnLvl,
+			formatter: TextFormatter{Color: true},
+		},
+		"2015-07-02T13:28:42 \x1b[36m[INFO ]\x1b[0m /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     InfoLvl,
+			formatter: TextFormatter{Color: true, AlignLevels: true},
+		},
+		"2015-07-02T13:28:42 \x1b[31mE\x1b[0m /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     ErrorLvl,
+			formatter: TextFormatter{Color: true, CompactLevel: true},
+		},
+		"⚠️ 2015-07-02T13:28:42 [WARN] /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     WarnLvl,
+			formatter: TextFormatter{Icons: DefaultLevelIcons()},
+		},
+		"2015-07-02T13:28:42 [INFO] /my/test/file.go:145: ": {
+			now:       time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+			file:      "/my/test/file.go",
+			line:      145,
+			level:     InfoLvl,
+			formatter: TextFormatter{Icons: map[Level]string{ErrorLvl: "!!"}},
+		},
 	}
 	for out, in := range headers {
 		var buf []byte
-		formatHeader(&buf, in.now, in.file, in.line, in.level)
+		in.formatter.formatHeader(&buf, in.now, in.file, in.line, in.level)
 		if string(buf) != out {
 			t.Errorf("Expected output to be '%s', got '%s' from %+v\n", out, string(buf), in)
 		}
@@ -156,7 +278,7 @@ func TestOutput(t *testing.T) {
 	year, month, day := time.Now().Date()
 	hour, minute, second := time.Now().Clock()
 	path := strings.TrimRight(os.Getenv("GOPATH"), "/") + "/src/github.com/DramaFever/go-logging/log.go"
-	line := 471
+	line := 884
 	if testing.Coverage() > 0 {
 		path = "github.com/DramaFever/go-logging/_test/_obj_test/log.go"
 		line = 457
@@ -204,12 +326,15 @@ func TestHelpers(t *testing.T) {
 	year, month, day := time.Now().Date()
 	hour, minute, second := time.Now().Clock()
 	path := strings.TrimRight(os.Getenv("GOPATH"), "/") + "/src/github.com/DramaFever/go-logging/log.go"
-	line := 405
+	line := 825
 	if testing.Coverage() > 0 {
 		path = "github.com/DramaFever/go-logging/_test/_obj_test/log.go"
 		line = 392
 	}
 	for pos, test := range levelTests {
+		if test.stmtLevel == DebugLvl && !debugEnabled {
+			continue
+		}
 		buf.Reset()
 		log = log.SetLevel(test.logLevel)
 		var f func(...interface{})
@@ -231,7 +356,7 @@ func TestHelpers(t *testing.T) {
 			t.Errorf("Unexpected level: %s\n", test.stmtLevel)
 		}
 		f("Test number", pos)
-		line = 405
+		line = 825
 		if testing.Coverage() > 0 {
 			line = 392
 		}
@@ -247,7 +372,7 @@ func TestHelpers(t *testing.T) {
 
 		buf.Reset()
 		ff("Test number %d", pos)
-		line = 412
+		line = 832
 		if testing.Coverage() > 0 {
 			line = 401
 		}
@@ -261,3 +386,476 @@ func TestHelpers(t *testing.T) {
 		}
 	}
 }
+
+type errWriter struct {
+	err error
+}
+
+func (w errWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestSetErrorHandler(t *testing.T) {
+	writeErr := errors.New("disk full")
+	log, err := New(DebugLvl, errWriter{err: writeErr}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var handled []error
+	log = log.SetErrorHandler(func(err error) {
+		handled = append(handled, err)
+	})
+	log.Info("Test output")
+	log.Warnf("Test %s", "output")
+	if len(handled) != 2 {
+		t.Fatalf("Expected 2 errors to be handled, got %d instead\n", len(handled))
+	}
+	for _, err := range handled {
+		if err != writeErr {
+			t.Errorf("Expected error to be %+v, got %+v instead\n", writeErr, err)
+		}
+	}
+}
+
+func TestFlush(t *testing.T) {
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	log, err := New(DebugLvl, out, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Info("Test output")
+	if buf.Len() != 0 {
+		t.Errorf("Expected output to be buffered, got '%s'\n", buf.String())
+	}
+	if err := log.Flush(); err != nil {
+		t.Errorf("Unexpected error: %+v\n", err)
+	}
+	if !strings.HasSuffix(buf.String(), "Test output\n") {
+		t.Errorf("Expected output to be flushed, got '%s'\n", buf.String())
+	}
+
+	log = log.SetOutput(&buf)
+	if err := log.Flush(); err != nil {
+		t.Errorf("Expected Flush to be a no-op, got %+v\n", err)
+	}
+}
+
+// failingCloser is an io.WriteCloser that counts how often it is closed, and fails every time.
+type failingCloser struct {
+	bytes.Buffer
+	closes int
+}
+
+func (c *failingCloser) Close() error {
+	c.closes++
+	return errors.New("already closed")
+}
+
+func TestCloseIdempotent(t *testing.T) {
+	out := &failingCloser{}
+	log, err := New(DebugLvl, out, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	child := log.Child(Fields{"k": "v"})
+	if err := log.Close(); err == nil || err.Error() != "already closed" {
+		t.Errorf("Expected the output's error, got %+v instead\n", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Errorf("Expected a second Close to be a no-op, got %+v instead\n", err)
+	}
+	if err := child.Close(); err != nil {
+		t.Errorf("Expected closing a child to be a no-op, got %+v instead\n", err)
+	}
+	if out.closes != 1 {
+		t.Errorf("Expected the output to be closed once, got %d instead\n", out.closes)
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	first, second := errors.New("first"), errors.New("second")
+	if err := combineErrors(nil, nil); err != nil {
+		t.Errorf("Expected nil, got %+v instead\n", err)
+	}
+	if err := combineErrors(nil, second); err != second {
+		t.Errorf("Expected %+v, got %+v instead\n", second, err)
+	}
+	if err := combineErrors(first, second); err == nil || err.Error() != "first; second" {
+		t.Errorf("Expected 'first; second', got %+v instead\n", err)
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	frozen := time.Date(2015, time.July, 2, 13, 28, 42, 0, time.FixedZone("EDT", -4*60*60))
+	log = log.SetClock(func() time.Time { return frozen })
+	log.Info("Test output")
+	if !strings.HasPrefix(buf.String(), "2015-07-02T13:28:42 [INFO] ") {
+		t.Errorf("Expected output to start with the frozen time, got '%s' instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log, err = NewWithOptions(WithOutput(&buf), WithClock(func() time.Time { return frozen }), WithUTC())
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Info("Test output")
+	if !strings.HasPrefix(buf.String(), "2015-07-02T17:28:42 [INFO] ") {
+		t.Errorf("Expected output to start with the frozen time in UTC, got '%s' instead\n", buf.String())
+	}
+}
+
+func TestIncludeGoroutineID(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.SetIncludeGoroutineID(true)
+	ids := make(chan uint64, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			ids <- goroutineID()
+		}()
+	}
+	first, second := <-ids, <-ids
+	if first == 0 || second == 0 || first == second {
+		t.Errorf("Expected distinct goroutine IDs, got %d and %d\n", first, second)
+	}
+
+	log.Info("Test output")
+	expected := fmt.Sprintf(": g%d Test output\n", goroutineID())
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			child := log.Child(Fields{"worker": i})
+			for j := 0; j < 50; j++ {
+				child.Infof("iteration %d", j)
+				log.Debug("maybe suppressed")
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				log.SetLevel(DebugLvl)
+				log.SetLevel(InfoLvl)
+				log.SetOutput(&buf)
+			}
+		}()
+	}
+	wg.Wait()
+	if lines := strings.Count(buf.String(), "[INFO]"); lines != 200 {
+		t.Errorf("Expected 200 INFO entries, got %d instead\n", lines)
+	}
+}
+
+func TestNilLogger(t *testing.T) {
+	var log *Logger
+	log.Info("discarded")
+	log.Errorf("discarded %s", "too")
+}
+
+func TestSprintln(t *testing.T) {
+	type sprintlnTest struct {
+		msg []interface{}
+	}
+	sprintlnTests := []sprintlnTest{
+		{msg: []interface{}{"single string"}},
+		{msg: []interface{}{"trailing newline\n"}},
+		{msg: []interface{}{""}},
+		{msg: []interface{}{42}},
+		{msg: []interface{}{"two", "strings"}},
+		{msg: []interface{}{"mixed", 42, errors.New("args")}},
+	}
+	for _, test := range sprintlnTests {
+		expected := strings.TrimSuffix(fmt.Sprintln(test.msg...), "\n")
+		result := strings.TrimSuffix(sprintln(test.msg...), "\n")
+		if result != expected {
+			t.Errorf("Expected %#v to be '%s', got '%s' instead\n", test.msg, expected, result)
+		}
+	}
+}
+
+func TestSetLineTerminator(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	for _, terminator := range []string{"\r\n", "\x00", ""} {
+		buf.Reset()
+		log.SetLineTerminator(terminator)
+		log.Info("Test output")
+		if !strings.HasSuffix(buf.String(), ": Test output"+terminator) {
+			t.Errorf("Expected output to end with %q, got %q instead\n", terminator, buf.String())
+		}
+	}
+}
+
+// syncRecorder is an io.Writer with a Sync method that counts how often it is called.
+type syncRecorder struct {
+	bytes.Buffer
+	syncs int
+}
+
+func (s *syncRecorder) Sync() error {
+	s.syncs++
+	return nil
+}
+
+func TestSetSync(t *testing.T) {
+	out := &syncRecorder{}
+	log, err := New(DebugLvl, out, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Info("not synced")
+	if out.syncs != 0 {
+		t.Errorf("Expected no syncs by default, got %d instead\n", out.syncs)
+	}
+	log.SetSync(true)
+	log.Info("synced")
+	log.Warn("synced")
+	if out.syncs != 2 {
+		t.Errorf("Expected 2 syncs, got %d instead\n", out.syncs)
+	}
+}
+
+func TestOnLevelChange(t *testing.T) {
+	log, err := New(InfoLvl, ioutil.Discard, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var changes []string
+	log.OnLevelChange(func(old, new Level) {
+		changes = append(changes, "first "+string(old)+"->"+string(new))
+	})
+	log.OnLevelChange(func(old, new Level) {
+		// Using the Logger from a callback must not deadlock.
+		changes = append(changes, "second "+string(log.GetLevel()))
+	})
+	child := log.Child(nil).OnLevelChange(func(old, new Level) {
+		changes = append(changes, "child")
+	})
+	log.SetLevel(DebugLvl)
+	expected := []string{"first INFO->DEBUG", "second DEBUG"}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v instead\n", expected, changes)
+	}
+
+	changes = nil
+	child.SetLevel(WarnLvl)
+	expected = []string{"first INFO->WARN", "second DEBUG", "child"}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v instead\n", expected, changes)
+	}
+}
+
+func TestGetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if log.GetOutput() != &buf {
+		t.Errorf("Expected %p, got %p instead\n", &buf, log.GetOutput())
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		log.SetOutput(ioutil.Discard)
+	}()
+	go func() {
+		defer wg.Done()
+		log.GetOutput()
+	}()
+	wg.Wait()
+	if log.GetOutput() != ioutil.Discard {
+		t.Errorf("Expected %v, got %v instead\n", ioutil.Discard, log.GetOutput())
+	}
+}
+
+// countingFormatter is a Formatter that counts the entries it formats.
+type countingFormatter struct {
+	count *int
+}
+
+func (f countingFormatter) Format(buf *[]byte, e *Entry) {
+	*f.count++
+	*buf = append(*buf, e.Message...)
+}
+
+func TestNewDiscard(t *testing.T) {
+	log := NewDiscard(WarnLvl)
+	if log.GetLevel() != WarnLvl || log.GetOutput() != Discard {
+		t.Errorf("Expected a %s Logger writing to Discard, got %s and %v instead\n", WarnLvl, log.GetLevel(), log.GetOutput())
+	}
+	formatted := 0
+	log.formatter = countingFormatter{count: &formatted}
+	log.Warn("dropped")
+	hooked := levelCounter{}
+	log.AddHook(hooked)
+	log.Error("dropped")
+	if formatted != 0 {
+		t.Errorf("Expected nothing to be formatted, got %d instead\n", formatted)
+	}
+	if hooked[ErrorLvl] != 1 {
+		t.Errorf("Expected Hooks to still fire, got %v instead\n", hooked)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.Warn("written")
+	if formatted != 1 || buf.String() != "written\n" {
+		t.Errorf("Expected output once it is redirected, got '%s' instead\n", buf.String())
+	}
+}
+
+func TestNewWithContext(t *testing.T) {
+	out := &closeRecorder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	log, err := NewWithContext(ctx, WithOutput(out))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	child := log.Child(Fields{"k": "v"})
+	child.Info("before cancellation")
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for !out.isClosed() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !out.isClosed() {
+		t.Fatal("Expected the output to be closed once the Context was done")
+	}
+	log.Info("after cancellation")
+	child.Info("after cancellation")
+	out.Lock()
+	defer out.Unlock()
+	if out.writes != 1 {
+		t.Errorf("Expected writes after cancellation to do nothing, got %d writes instead\n", out.writes)
+	}
+}
+
+func TestNewWithContextClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+	log, err := NewWithContext(context.Background(), WithOutput(&closeRecorder{}))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("Expected Close to stop watching the Context, got %d goroutines instead of %d\n", n, before)
+	}
+}
+
+// flakyWriter is an io.Writer that fails with err the first failures times it is written to.
+type flakyWriter struct {
+	bytes.Buffer
+	failures int
+	attempts int
+	err      error
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.attempts++
+	if w.failures > 0 {
+		w.failures--
+		return 0, w.err
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestSetWriteRetry(t *testing.T) {
+	temporary := &os.PathError{Op: "write", Path: "pipe", Err: syscall.EAGAIN}
+	cases := []struct {
+		failures, attempts int
+		err                error
+		expectedAttempts   int
+		expectedErr        error
+	}{
+		{failures: 2, attempts: 2, err: temporary, expectedAttempts: 3},
+		{failures: 3, attempts: 2, err: temporary, expectedAttempts: 3, expectedErr: temporary},
+		{failures: 1, attempts: 0, err: temporary, expectedAttempts: 1, expectedErr: temporary},
+		{failures: 1, attempts: 2, err: errors.New("permanent"), expectedAttempts: 1},
+	}
+	for _, c := range cases {
+		out := &flakyWriter{failures: c.failures, err: c.err}
+		log, err := New(InfoLvl, out, "", nil)
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+		var handled error
+		log.SetWriteRetry(c.attempts, time.Microsecond).SetErrorHandler(func(err error) { handled = err })
+		log.Info("retried")
+		if out.attempts != c.expectedAttempts {
+			t.Errorf("Expected %d attempts, got %d instead\n", c.expectedAttempts, out.attempts)
+		}
+		if c.expectedErr != nil && handled != c.expectedErr {
+			t.Errorf("Expected %+v to be handled, got %+v instead\n", c.expectedErr, handled)
+		}
+		if handled == nil && !strings.HasSuffix(out.String(), "retried\n") {
+			t.Errorf("Expected the entry to be written, got '%s' instead\n", out.String())
+		}
+	}
+}
+
+func TestSetFallback(t *testing.T) {
+	out := &flakyWriter{failures: 1, err: errors.New("sink down")}
+	log, err := New(InfoLvl, out, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	var fallback bytes.Buffer
+	var handled error
+	log.SetFallback(&fallback).SetErrorHandler(func(err error) { handled = err })
+	if log.FallbackWrites() != 0 {
+		t.Errorf("Expected no fallback writes, got %d instead\n", log.FallbackWrites())
+	}
+	log.Child(Fields{"k": "v"}).Info("kept")
+	log.Info("primary")
+	if !strings.HasSuffix(fallback.String(), "kept k=v\n") {
+		t.Errorf("Expected the failed entry in the fallback, got '%s' instead\n", fallback.String())
+	}
+	if !strings.HasSuffix(out.String(), "primary\n") || strings.Contains(fallback.String(), "primary") {
+		t.Errorf("Expected later entries to go to the output, got '%s' instead\n", out.String())
+	}
+	if handled != nil {
+		t.Errorf("Expected no error to be handled, got %+v instead\n", handled)
+	}
+	if log.FallbackWrites() != 1 {
+		t.Errorf("Expected 1 fallback write, got %d instead\n", log.FallbackWrites())
+	}
+
+	log.SetFallback(errWriter{err: errors.New("fallback down")})
+	out.failures = 1
+	log.Info("lost")
+	if handled == nil || handled.Error() != "sink down" {
+		t.Errorf("Expected the output's error to be handled, got %+v instead\n", handled)
+	}
+}
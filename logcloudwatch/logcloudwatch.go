@@ -0,0 +1,291 @@
+// Package logcloudwatch provides a writer that sends log output to AWS CloudWatch Logs.
+package logcloudwatch
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+// These are the limits CloudWatch Logs places on each PutLogEvents call.
+const (
+	maxBatchEvents = 10000
+	maxBatchBytes  = 1048576
+	// eventOverhead is the number of bytes CloudWatch counts for each event on top of its message.
+	eventOverhead   = 26
+	maxMessageBytes = 262144 - eventOverhead
+)
+
+const (
+	defaultFlushInterval = 5 * time.Second
+	// maxPutAttempts bounds the number of times a batch is resent after CloudWatch rejects its sequence
+	// token or reports its stream missing.
+	maxPutAttempts = 3
+	// maxQueued is the number of batches that can wait to be sent before new ones overflow.
+	maxQueued = 16
+)
+
+// API is the part of the CloudWatch Logs client the Writer uses. It is satisfied by
+// *cloudwatchlogs.CloudWatchLogs and cloudwatchlogsiface.CloudWatchLogsAPI.
+type API interface {
+	CreateLogGroup(*cloudwatchlogs.CreateLogGroupInput) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	CreateLogStream(*cloudwatchlogs.CreateLogStreamInput) (*cloudwatchlogs.CreateLogStreamOutput, error)
+	PutLogEvents(*cloudwatchlogs.PutLogEventsInput) (*cloudwatchlogs.PutLogEventsOutput, error)
+}
+
+// Config controls where a Writer sends log output, and how it batches it.
+type Config struct {
+	// Group is the name of the log group. It is created if it doesn't exist.
+	Group string
+	// Stream is the name of the log stream within Group, like the ECS task ID. It is created if it
+	// doesn't exist.
+	Stream string
+	// FlushInterval is the longest a line waits before it is sent. It defaults to five seconds.
+	FlushInterval time.Duration
+	// Fallback, if set, is written each batch that could not be sent, or that overflowed the queue of
+	// batches waiting to be sent, like os.Stderr.
+	Fallback io.Writer
+}
+
+// batch is a batch of events waiting to be sent. If done is non-nil, it is closed once the batch has been
+// handled.
+type batch struct {
+	events []*cloudwatchlogs.InputLogEvent
+	done   chan struct{}
+}
+
+// Writer is an io.WriteCloser that sends log output to a CloudWatch Logs stream, one event per line.
+// Lines are collected into batches within CloudWatch's limits of 10,000 events and 1MB per call, which
+// are sent when full or after the flush interval, from a background goroutine so logging never waits on
+// the network. If CloudWatch falls so far behind that 16 batches are waiting to be sent, further batches
+// are written to the Fallback, or dropped if there is none, rather than making the caller wait. Only
+// Flush and Close wait for batches to be sent. Lines over CloudWatch's 256KB event limit are truncated.
+// It is safe for concurrent use. Close must be called to send the final batch and stop the background
+// goroutine.
+type Writer struct {
+	client API
+	config Config
+	now    func() time.Time
+
+	mu      sync.Mutex
+	events  []*cloudwatchlogs.InputLogEvent
+	size    int
+	timer   *time.Timer
+	closed  bool
+	batches chan batch
+	stopped chan struct{}
+
+	fallbackMu sync.Mutex
+
+	// These are only used by the background goroutine.
+	created bool
+	token   *string
+}
+
+// NewWriter returns a Writer that sends log lines to the stream described by config, using client, like
+// one returned by cloudwatchlogs.New.
+func NewWriter(client API, config Config) *Writer {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	w := &Writer{
+		client:  client,
+		config:  config,
+		now:     time.Now,
+		batches: make(chan batch, maxQueued),
+		stopped: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// WritesEntries reports that each Write is sent as a separate event, so the Logger's SetFlushInterval
+// leaves the Writer unbuffered. See logging.EntryWriter.
+func (w *Writer) WritesEntries() bool {
+	return true
+}
+
+// Write adds p to the current batch as a single event, sending the batch if it is full.
+func (w *Writer) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+	if msg == "" {
+		// CloudWatch rejects empty events.
+		return len(p), nil
+	}
+	if len(msg) > maxMessageBytes {
+		msg = msg[:maxMessageBytes]
+	}
+	size := len(msg) + eventOverhead
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if len(w.events) >= maxBatchEvents || w.size+size > maxBatchBytes {
+		w.send(nil, false)
+	}
+	w.events = append(w.events, &cloudwatchlogs.InputLogEvent{
+		Message:   aws.String(msg),
+		Timestamp: aws.Int64(w.now().UnixNano() / int64(time.Millisecond)),
+	})
+	w.size += size
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.config.FlushInterval, w.expire)
+	}
+	return len(p), nil
+}
+
+// Flush sends the current batch, and waits for every batch to be handled.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	done := make(chan struct{})
+	w.send(done, true)
+	w.mu.Unlock()
+	<-done
+	return nil
+}
+
+// Close sends the current batch, waits for every batch to be handled, and stops the background
+// goroutine.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.send(nil, true)
+	w.closed = true
+	close(w.batches)
+	w.mu.Unlock()
+	<-w.stopped
+	return nil
+}
+
+// expire sends the current batch once the flush interval has passed.
+func (w *Writer) expire() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.send(nil, false)
+	}
+}
+
+// send queues the current batch for the background goroutine and starts a new one. done, if non-nil, is
+// closed once the queued batch has been handled, even if it was empty. Unless wait is set, send doesn't
+// wait for room in the queue; if it is full, the batch is written to the Fallback instead. The caller
+// must hold w.mu.
+func (w *Writer) send(done chan struct{}, wait bool) {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.events) == 0 && done == nil {
+		return
+	}
+	b := batch{events: w.events, done: done}
+	w.events = nil
+	w.size = 0
+	if wait {
+		w.batches <- b
+		return
+	}
+	select {
+	case w.batches <- b:
+	default:
+		w.fallback(b.events)
+	}
+}
+
+// run sends batches until the batches channel is closed.
+func (w *Writer) run() {
+	defer close(w.stopped)
+	for b := range w.batches {
+		if len(b.events) > 0 {
+			w.put(b.events)
+		}
+		if b.done != nil {
+			close(b.done)
+		}
+	}
+}
+
+// put sends events to the stream, creating it and tracking its sequence token as needed, and writes them
+// to the Fallback if they could not be sent.
+func (w *Writer) put(events []*cloudwatchlogs.InputLogEvent) {
+	// CloudWatch requires the events in a batch to be in chronological order.
+	sort.SliceStable(events, func(i, j int) bool {
+		return *events[i].Timestamp < *events[j].Timestamp
+	})
+	for attempt := 0; attempt < maxPutAttempts; attempt++ {
+		if !w.created {
+			if err := w.create(); err != nil {
+				break
+			}
+		}
+		out, err := w.client.PutLogEvents(&cloudwatchlogs.PutLogEventsInput{
+			LogGroupName:  aws.String(w.config.Group),
+			LogStreamName: aws.String(w.config.Stream),
+			LogEvents:     events,
+			SequenceToken: w.token,
+		})
+		switch e := err.(type) {
+		case nil:
+			w.token = out.NextSequenceToken
+			return
+		case *cloudwatchlogs.InvalidSequenceTokenException:
+			w.token = e.ExpectedSequenceToken
+			continue
+		case *cloudwatchlogs.DataAlreadyAcceptedException:
+			w.token = e.ExpectedSequenceToken
+			return
+		case *cloudwatchlogs.ResourceNotFoundException:
+			w.created = false
+			w.token = nil
+			continue
+		}
+		break
+	}
+	w.fallback(events)
+}
+
+// fallback writes events to the Fallback, if there is one. Batches can overflow while the background
+// goroutine is writing a failed one, so writes are serialized.
+func (w *Writer) fallback(events []*cloudwatchlogs.InputLogEvent) {
+	if w.config.Fallback == nil {
+		return
+	}
+	w.fallbackMu.Lock()
+	defer w.fallbackMu.Unlock()
+	for _, e := range events {
+		io.WriteString(w.config.Fallback, *e.Message+"\n")
+	}
+}
+
+// create creates the log group and stream, if they don't already exist.
+func (w *Writer) create() error {
+	_, err := w.client.CreateLogGroup(&cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(w.config.Group),
+	})
+	if _, exists := err.(*cloudwatchlogs.ResourceAlreadyExistsException); err != nil && !exists {
+		return err
+	}
+	_, err = w.client.CreateLogStream(&cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(w.config.Group),
+		LogStreamName: aws.String(w.config.Stream),
+	})
+	if _, exists := err.(*cloudwatchlogs.ResourceAlreadyExistsException); err != nil && !exists {
+		return err
+	}
+	w.created = true
+	return nil
+}
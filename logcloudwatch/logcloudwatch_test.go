@@ -0,0 +1,186 @@
+package logcloudwatch
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+)
+
+// fakeAPI records the calls made to it, and fails PutLogEvents with each error in putErrors in turn. If
+// release is set, PutLogEvents waits for it to be closed.
+type fakeAPI struct {
+	release    chan struct{}
+	mu         sync.Mutex
+	groups     []string
+	streams    []string
+	puts       []*cloudwatchlogs.PutLogEventsInput
+	createErr  error
+	putErrors  []error
+	tokenCount int
+}
+
+func (f *fakeAPI) CreateLogGroup(in *cloudwatchlogs.CreateLogGroupInput) (*cloudwatchlogs.CreateLogGroupOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.groups = append(f.groups, aws.StringValue(in.LogGroupName))
+	return &cloudwatchlogs.CreateLogGroupOutput{}, f.createErr
+}
+
+func (f *fakeAPI) CreateLogStream(in *cloudwatchlogs.CreateLogStreamInput) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.streams = append(f.streams, aws.StringValue(in.LogStreamName))
+	return &cloudwatchlogs.CreateLogStreamOutput{}, f.createErr
+}
+
+func (f *fakeAPI) PutLogEvents(in *cloudwatchlogs.PutLogEventsInput) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	if f.release != nil {
+		<-f.release
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.puts = append(f.puts, in)
+	if len(f.putErrors) > 0 {
+		err := f.putErrors[0]
+		f.putErrors = f.putErrors[1:]
+		return nil, err
+	}
+	f.tokenCount++
+	return &cloudwatchlogs.PutLogEventsOutput{NextSequenceToken: aws.String(strings.Repeat("t", f.tokenCount))}, nil
+}
+
+func messages(in *cloudwatchlogs.PutLogEventsInput) []string {
+	var msgs []string
+	for _, e := range in.LogEvents {
+		msgs = append(msgs, aws.StringValue(e.Message))
+	}
+	return msgs
+}
+
+func TestWriter(t *testing.T) {
+	api := &fakeAPI{createErr: &cloudwatchlogs.ResourceAlreadyExistsException{}}
+	w := NewWriter(api, Config{Group: "app", Stream: "task-1", FlushInterval: time.Hour})
+	w.Write([]byte("first\n"))
+	w.Write([]byte("\n"))
+	w.Write([]byte("second\n"))
+	w.Flush()
+	w.Write([]byte("third\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if len(api.groups) != 1 || api.groups[0] != "app" || len(api.streams) != 1 || api.streams[0] != "task-1" {
+		t.Errorf("Expected the group and stream to be created once, got %v and %v instead\n", api.groups, api.streams)
+	}
+	if len(api.puts) != 2 {
+		t.Fatalf("Expected 2 calls to PutLogEvents, got %d instead\n", len(api.puts))
+	}
+	if got := messages(api.puts[0]); strings.Join(got, ",") != "first,second" {
+		t.Errorf("Expected the first batch to be [first second], got %v instead\n", got)
+	}
+	if api.puts[0].SequenceToken != nil {
+		t.Errorf("Expected no sequence token on the first call, got %q instead\n", *api.puts[0].SequenceToken)
+	}
+	if got := aws.StringValue(api.puts[1].SequenceToken); got != "t" {
+		t.Errorf("Expected the second call to use the token from the first, got %q instead\n", got)
+	}
+	if _, err := w.Write([]byte("closed\n")); err == nil {
+		t.Error("Expected an error writing to a closed Writer")
+	}
+}
+
+func TestWriterInvalidSequenceToken(t *testing.T) {
+	api := &fakeAPI{putErrors: []error{
+		&cloudwatchlogs.InvalidSequenceTokenException{ExpectedSequenceToken: aws.String("expected")},
+	}}
+	w := NewWriter(api, Config{Group: "app", Stream: "task-1"})
+	w.Write([]byte("message\n"))
+	w.Close()
+
+	if len(api.puts) != 2 {
+		t.Fatalf("Expected the batch to be resent once, got %d calls instead\n", len(api.puts))
+	}
+	if got := aws.StringValue(api.puts[1].SequenceToken); got != "expected" {
+		t.Errorf("Expected the batch to be resent with the expected token, got %q instead\n", got)
+	}
+}
+
+func TestWriterFallback(t *testing.T) {
+	var fallback bytes.Buffer
+	api := &fakeAPI{putErrors: []error{errors.New("throttled")}}
+	w := NewWriter(api, Config{Group: "app", Stream: "task-1", Fallback: &fallback})
+	w.Write([]byte("lost\n"))
+	w.Close()
+
+	if fallback.String() != "lost\n" {
+		t.Errorf("Expected the batch to be written to the fallback, got %q instead\n", fallback.String())
+	}
+}
+
+func TestWriterBatchLimits(t *testing.T) {
+	api := &fakeAPI{}
+	w := NewWriter(api, Config{Group: "app", Stream: "task-1", FlushInterval: time.Hour})
+	for i := 0; i < maxBatchEvents+1; i++ {
+		w.Write([]byte("x\n"))
+	}
+	big := strings.Repeat("y", maxMessageBytes+100)
+	for i := 0; i < 4; i++ {
+		w.Write([]byte(big))
+	}
+	w.Close()
+
+	for _, put := range api.puts {
+		size := 0
+		for _, e := range put.LogEvents {
+			size += len(aws.StringValue(e.Message)) + eventOverhead
+		}
+		if len(put.LogEvents) > maxBatchEvents || size > maxBatchBytes {
+			t.Errorf("Expected batches within CloudWatch's limits, got %d events in %d bytes\n", len(put.LogEvents), size)
+		}
+	}
+	if len(api.puts) != 3 {
+		t.Errorf("Expected 3 batches, got %d instead\n", len(api.puts))
+	}
+	if got := len(aws.StringValue(api.puts[2].LogEvents[0].Message)); got != maxMessageBytes {
+		t.Errorf("Expected long lines to be truncated to %d bytes, got %d instead\n", maxMessageBytes, got)
+	}
+}
+
+func TestWriterOverflow(t *testing.T) {
+	api := &fakeAPI{release: make(chan struct{})}
+	var fallback bytes.Buffer
+	w := NewWriter(api, Config{Group: "app", Stream: "task-1", FlushInterval: time.Hour, Fallback: &fallback})
+
+	// Four lines this long fill a batch, so each fifth line sends one.
+	big := []byte(strings.Repeat("z", maxMessageBytes) + "\n")
+	lines := 4 * (maxQueued + 3)
+	written := make(chan struct{})
+	go func() {
+		for i := 0; i < lines; i++ {
+			w.Write(big)
+		}
+		close(written)
+	}()
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Write not to wait for CloudWatch")
+	}
+	close(api.release)
+	w.Close()
+
+	sent := 0
+	for _, put := range api.puts {
+		sent += len(put.LogEvents)
+	}
+	overflowed := strings.Count(fallback.String(), "\n")
+	if overflowed == 0 || sent+overflowed != lines {
+		t.Errorf("Expected the overflow in the fallback, got %d sent and %d in the fallback instead\n", sent, overflowed)
+	}
+}
@@ -0,0 +1,4 @@
+// Package logeventlog provides a writer that sends log output to the Windows Event Log, so the entries of
+// a Windows service show up in the Event Viewer. It is only available on Windows; on other platforms the
+// package is empty.
+package logeventlog
@@ -0,0 +1,127 @@
+package logeventlog
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+
+	"github.com/DramaFever/go-logging"
+)
+
+const (
+	// DefaultEventID is the event ID entries are reported with, unless Config sets another.
+	DefaultEventID = 1
+	// maxEventID is the highest event ID the EventCreate.exe message file, which sources are registered
+	// with, defines.
+	maxEventID = 1000
+	// maxMessageLength is the longest string, in characters, the Event Log accepts in an event.
+	maxMessageLength = 31839
+)
+
+// ErrEventID is returned by NewWriter when Config has an event ID outside 1 to 1000.
+var ErrEventID = errors.New("logeventlog: event IDs must be between 1 and 1000")
+
+// Config controls the event source a Writer reports to, and the event IDs it reports entries with.
+type Config struct {
+	// Source is the name of the event source, usually the name of the service.
+	Source string
+	// InfoEventID, WarningEventID, and ErrorEventID are the event IDs entries are reported with, by
+	// severity, so they can be filtered in the Event Viewer. Each defaults to DefaultEventID. Sources are
+	// registered with the EventCreate.exe message file, which only defines event IDs 1 to 1000; the Event
+	// Viewer can't display events with other IDs.
+	InfoEventID    uint32
+	WarningEventID uint32
+	ErrorEventID   uint32
+}
+
+// eventLog is the part of *eventlog.Log a Writer uses.
+type eventLog interface {
+	Info(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+	Close() error
+}
+
+// Writer is a logging.LevelWriter that reports each entry to the Windows Event Log, as an information,
+// warning, or error event by the entry's Level. Debug entries are reported as information events.
+type Writer struct {
+	log    eventLog
+	config Config
+}
+
+// NewWriter registers config.Source as an event source in the Application log, if it isn't registered
+// already, and returns a Writer that reports to it. Registering a source writes to the registry, so it
+// needs administrator rights the first time; installers usually do it, after which the service can run
+// as any user.
+func NewWriter(config Config) (*Writer, error) {
+	for _, id := range []*uint32{&config.InfoEventID, &config.WarningEventID, &config.ErrorEventID} {
+		if *id == 0 {
+			*id = DefaultEventID
+		}
+		if *id > maxEventID {
+			return nil, ErrEventID
+		}
+	}
+	err := eventlog.InstallAsEventCreate(config.Source, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil && !strings.HasSuffix(err.Error(), "registry key already exists") {
+		return nil, err
+	}
+	log, err := eventlog.Open(config.Source)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{log: log, config: config}, nil
+}
+
+// LogToEventLog creates a new Logger that reports entries to the Windows Event Log, under the event
+// source config.Source. See NewWriter for details. The event source is closed by the Logger's Close
+// method.
+//
+// If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
+// are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
+// the version of your software running, etc.
+func LogToEventLog(level logging.Level, config Config, sentry string, sentryTags map[string]string) (*logging.Logger, error) {
+	w, err := NewWriter(config)
+	if err != nil {
+		return nil, err
+	}
+	return logging.New(level, w, sentry, sentryTags)
+}
+
+// Write reports p as an information event.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.WriteLevel(logging.InfoLvl, p)
+}
+
+// WriteLevel reports p as an event with the severity that corresponds to level. The trailing newline is
+// dropped, and messages longer than the Event Log allows are truncated.
+func (w *Writer) WriteLevel(level logging.Level, p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\r\n")
+	if len(msg) > maxMessageLength {
+		n := maxMessageLength
+		for n > 0 && !utf8.RuneStart(msg[n]) {
+			n--
+		}
+		msg = msg[:n]
+	}
+	var err error
+	switch level {
+	case logging.DebugLvl, logging.InfoLvl:
+		err = w.log.Info(w.config.InfoEventID, msg)
+	case logging.WarnLvl:
+		err = w.log.Warning(w.config.WarningEventID, msg)
+	default:
+		err = w.log.Error(w.config.ErrorEventID, msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the event source.
+func (w *Writer) Close() error {
+	return w.log.Close()
+}
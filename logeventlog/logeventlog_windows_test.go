@@ -0,0 +1,86 @@
+package logeventlog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DramaFever/go-logging"
+)
+
+type event struct {
+	severity string
+	id       uint32
+	msg      string
+}
+
+type fakeEventLog struct {
+	events []event
+	closed bool
+}
+
+func (f *fakeEventLog) Info(eid uint32, msg string) error {
+	f.events = append(f.events, event{"info", eid, msg})
+	return nil
+}
+
+func (f *fakeEventLog) Warning(eid uint32, msg string) error {
+	f.events = append(f.events, event{"warning", eid, msg})
+	return nil
+}
+
+func (f *fakeEventLog) Error(eid uint32, msg string) error {
+	f.events = append(f.events, event{"error", eid, msg})
+	return nil
+}
+
+func (f *fakeEventLog) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestWriteLevel(t *testing.T) {
+	fake := &fakeEventLog{}
+	w := &Writer{log: fake, config: Config{InfoEventID: 1, WarningEventID: 2, ErrorEventID: 3}}
+	log, err := logging.New(logging.DebugLvl, w, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Debug("debug")
+	log.Info("info")
+	log.Warn("warn")
+	log.Error("error")
+	log.Close()
+
+	expected := []event{{"info", 1, "debug"}, {"info", 1, "info"}, {"warning", 2, "warn"}, {"error", 3, "error"}}
+	if len(fake.events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v instead\n", len(expected), fake.events)
+	}
+	for i, e := range expected {
+		got := fake.events[i]
+		if got.severity != e.severity || got.id != e.id || !strings.HasSuffix(got.msg, ": "+e.msg) {
+			t.Errorf("Expected event %+v, got %+v instead\n", e, got)
+		}
+	}
+	if !fake.closed {
+		t.Error("Expected the event source to be closed with the Logger")
+	}
+}
+
+func TestWriteLevelTruncates(t *testing.T) {
+	fake := &fakeEventLog{}
+	w := &Writer{log: fake, config: Config{InfoEventID: 1}}
+	long := strings.Repeat("é", maxMessageLength)
+	if _, err := w.Write([]byte(long + "\n")); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	msg := fake.events[0].msg
+	if len(msg) > maxMessageLength || !strings.HasPrefix(long, msg) {
+		t.Errorf("Expected the message to be truncated to %d bytes on a character boundary, got %d bytes instead\n", maxMessageLength, len(msg))
+	}
+}
+
+func TestNewWriterEventID(t *testing.T) {
+	if _, err := NewWriter(Config{Source: "test", ErrorEventID: 1001}); err != ErrEventID {
+		t.Errorf("Expected ErrEventID, got %v instead\n", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Package loggrpc provides gRPC interceptors that log with the logging package.
+package loggrpc
+
+import (
+	"runtime/debug"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/DramaFever/go-logging"
+)
+
+// CodeToLevel returns the Level a call that finished with code is logged at. Codes that point to a bug
+// or a broken dependency in the server are logged at ErrorLvl, codes that may need attention at WarnLvl,
+// and codes that are a normal consequence of what the client asked for at InfoLvl.
+func CodeToLevel(code codes.Code) logging.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.Unauthenticated:
+		return logging.InfoLvl
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted, codes.FailedPrecondition,
+		codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return logging.WarnLvl
+	default:
+		return logging.ErrorLvl
+	}
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor that logs every unary call to l, with its method,
+// status code, and duration, at the Level CodeToLevel returns for its code. The handler's Context holds
+// a child of l with a "grpc.method" field, which can be retrieved with logging.LogFromContext.
+//
+// If the handler panics, the panic and its stack are logged at ErrorLvl, and the call fails with
+// codes.Internal instead of crashing the server.
+func UnaryServerInterceptor(l *logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		log := l.Child(logging.Fields{"grpc.method": info.FullMethod})
+		ctx = logging.SaveToContext(log, ctx)
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorf("panic in gRPC handler: %v\n%s", r, debug.Stack())
+				err = status.Errorf(codes.Internal, "panic: %v", r)
+			}
+			code := status.Code(err)
+			fields := logging.Fields{
+				"grpc.code":     code.String(),
+				"grpc.duration": time.Since(start),
+			}
+			if err != nil {
+				fields["error"] = err.Error()
+			}
+			log := log.Child(fields)
+			switch CodeToLevel(code) {
+			case logging.InfoLvl:
+				log.Info("finished unary call")
+			case logging.WarnLvl:
+				log.Warn("finished unary call")
+			default:
+				log.Error("finished unary call")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,77 @@
+package loggrpc
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/DramaFever/go-logging"
+	"github.com/DramaFever/go-logging/logtest"
+)
+
+var info = &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	log, recorder := logtest.NewRecorder()
+	interceptor := UnaryServerInterceptor(log)
+	var handlerLog *logging.Logger
+	resp, err := interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerLog = logging.LogFromContext(ctx)
+		return "resp", nil
+	})
+	if err != nil || resp != "resp" {
+		t.Fatalf("Expected the handler's response, got %v, %+v instead\n", resp, err)
+	}
+	if handlerLog.GetFields()["grpc.method"] != info.FullMethod {
+		t.Errorf("Expected the Context to hold a child Logger, got fields %v instead\n", handlerLog.GetFields())
+	}
+	entries := recorder.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d instead\n", len(entries))
+	}
+	e := entries[0]
+	if e.Level != logging.InfoLvl || e.Fields["grpc.code"] != "OK" || e.Fields["grpc.method"] != info.FullMethod {
+		t.Errorf("Expected an INFO entry for the call, got %+v instead\n", e)
+	}
+	if _, ok := e.Fields["grpc.duration"]; !ok {
+		t.Error("Expected the entry to include the duration")
+	}
+}
+
+func TestUnaryServerInterceptorError(t *testing.T) {
+	cases := map[error]logging.Level{
+		status.Error(codes.InvalidArgument, "bad"): logging.InfoLvl,
+		status.Error(codes.Unavailable, "down"):    logging.WarnLvl,
+		status.Error(codes.Internal, "broken"):     logging.ErrorLvl,
+		errors.New("plain"):                        logging.ErrorLvl,
+	}
+	for handlerErr, expected := range cases {
+		log, recorder := logtest.NewRecorder()
+		_, err := UnaryServerInterceptor(log)(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		})
+		if err != handlerErr {
+			t.Errorf("Expected %v, got %v instead\n", handlerErr, err)
+		}
+		entries := recorder.All()
+		if len(entries) != 1 || entries[0].Level != expected || entries[0].Fields["error"] != handlerErr.Error() {
+			t.Errorf("Expected one %s entry for %v, got %+v instead\n", expected, handlerErr, entries)
+		}
+	}
+}
+
+func TestUnaryServerInterceptorPanic(t *testing.T) {
+	log, recorder := logtest.NewRecorder()
+	_, err := UnaryServerInterceptor(log)(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("oh no")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("Expected %s, got %s instead\n", codes.Internal, status.Code(err))
+	}
+	recorder.AssertLogged(t, logging.ErrorLvl, "panic in gRPC handler: oh no")
+	recorder.AssertLogged(t, logging.ErrorLvl, "finished unary call")
+}
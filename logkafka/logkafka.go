@@ -0,0 +1,150 @@
+// Package logkafka provides a writer that produces log output to a Kafka topic.
+package logkafka
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/Shopify/sarama"
+)
+
+// Config controls where a Writer produces log output.
+type Config struct {
+	// Brokers are the addresses of the Kafka brokers to bootstrap from, like "kafka-1:9092".
+	Brokers []string
+	// Topic is the topic each log line is produced to.
+	Topic string
+	// Key, if set, returns the message key for a log line, like the value of its request ID field, so
+	// related lines land on the same partition. A nil key spreads lines across partitions.
+	Key func(line []byte) []byte
+	// Sarama, if set, configures the underlying producer, like its retries and buffer size. It defaults
+	// to sarama.NewConfig(). Errors are always returned by the producer, so the Writer can handle them.
+	Sarama *sarama.Config
+	// Fallback, if set, is written each line that could not be produced, like os.Stderr.
+	Fallback io.Writer
+}
+
+// producer is the part of sarama.AsyncProducer the Writer uses.
+type producer interface {
+	Input() chan<- *sarama.ProducerMessage
+	Errors() <-chan *sarama.ProducerError
+	Close() error
+}
+
+// ErrClosed is returned when writing to a Writer that has been closed.
+var ErrClosed = errors.New("logkafka: writer closed")
+
+// Writer is an io.WriteCloser that produces each log line as a Kafka message. Lines are buffered and
+// sent in batches by a sarama.AsyncProducer, which retries while brokers are unavailable. When its buffer
+// is full, Write blocks until there is room, so a Logger slows down instead of losing lines or using
+// unbounded memory. It is safe for concurrent use. Close must be called to send buffered lines.
+type Writer struct {
+	topic    string
+	key      func([]byte) []byte
+	fallback io.Writer
+	producer producer
+
+	mu      sync.RWMutex
+	closed  bool
+	stopped chan struct{}
+}
+
+// NewWriter returns a Writer that produces log lines as configured by config.
+func NewWriter(config Config) (*Writer, error) {
+	conf := config.Sarama
+	if conf == nil {
+		conf = sarama.NewConfig()
+	}
+	conf.Producer.Return.Errors = true
+	conf.Producer.Return.Successes = false
+	p, err := sarama.NewAsyncProducer(config.Brokers, conf)
+	if err != nil {
+		return nil, err
+	}
+	return newWriter(p, config), nil
+}
+
+// newWriter returns a Writer that produces log lines with p.
+func newWriter(p producer, config Config) *Writer {
+	w := &Writer{
+		topic:    config.Topic,
+		key:      config.Key,
+		fallback: config.Fallback,
+		producer: p,
+		stopped:  make(chan struct{}),
+	}
+	go w.handleErrors()
+	return w
+}
+
+// WritesEntries reports that each Write is produced as a separate message, so the Logger's
+// SetFlushInterval leaves the Writer unbuffered. See logging.EntryWriter.
+func (w *Writer) WritesEntries() bool {
+	return true
+}
+
+// Write produces p, without its trailing newline, as a single message.
+func (w *Writer) Write(p []byte) (int, error) {
+	line := p
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line = line[:n-1]
+	}
+	// The producer sends the message after Write returns, so it needs its own copy.
+	value := append([]byte(nil), line...)
+	msg := &sarama.ProducerMessage{Topic: w.topic, Value: sarama.ByteEncoder(value)}
+	if w.key != nil {
+		if key := w.key(value); key != nil {
+			msg.Key = sarama.ByteEncoder(key)
+		}
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	w.producer.Input() <- msg
+	return len(p), nil
+}
+
+// Close sends every buffered line, and shuts down the producer.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	err := w.producer.Close()
+	<-w.stopped
+	if errs, ok := err.(sarama.ProducerErrors); ok {
+		// The producer returns the errors it collected while closing, instead of sending them on Errors.
+		for _, e := range errs {
+			w.handleError(e)
+		}
+		err = nil
+	}
+	return err
+}
+
+// handleErrors handles lines that could not be produced, until the producer is closed.
+func (w *Writer) handleErrors() {
+	defer close(w.stopped)
+	for e := range w.producer.Errors() {
+		w.handleError(e)
+	}
+}
+
+// handleError writes the line in e to the Fallback, if there is one.
+func (w *Writer) handleError(e *sarama.ProducerError) {
+	if w.fallback == nil || e.Msg == nil || e.Msg.Value == nil {
+		return
+	}
+	line, err := e.Msg.Value.Encode()
+	if err != nil {
+		return
+	}
+	w.fallback.Write(append(line, '\n'))
+}
@@ -0,0 +1,97 @@
+package logkafka
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+// fakeProducer collects the messages it is given, and fails those whose value is "fail".
+type fakeProducer struct {
+	input  chan *sarama.ProducerMessage
+	errors chan *sarama.ProducerError
+	done   chan struct{}
+
+	mu       sync.Mutex
+	messages []*sarama.ProducerMessage
+}
+
+func newFakeProducer() *fakeProducer {
+	p := &fakeProducer{
+		input:  make(chan *sarama.ProducerMessage),
+		errors: make(chan *sarama.ProducerError, 16),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(p.done)
+		for msg := range p.input {
+			if v, _ := msg.Value.Encode(); string(v) == "fail" {
+				p.errors <- &sarama.ProducerError{Msg: msg, Err: errors.New("broker unavailable")}
+				continue
+			}
+			p.mu.Lock()
+			p.messages = append(p.messages, msg)
+			p.mu.Unlock()
+		}
+	}()
+	return p
+}
+
+func (p *fakeProducer) Input() chan<- *sarama.ProducerMessage { return p.input }
+func (p *fakeProducer) Errors() <-chan *sarama.ProducerError  { return p.errors }
+
+func (p *fakeProducer) Close() error {
+	close(p.input)
+	<-p.done
+	close(p.errors)
+	return nil
+}
+
+func TestWriter(t *testing.T) {
+	p := newFakeProducer()
+	var fallback bytes.Buffer
+	w := newWriter(p, Config{
+		Topic: "logs",
+		Key: func(line []byte) []byte {
+			if i := bytes.Index(line, []byte("request_id=")); i >= 0 {
+				return line[i+len("request_id="):]
+			}
+			return nil
+		},
+		Fallback: &fallback,
+	})
+
+	buf := []byte("served request_id=abc\n")
+	if n, err := w.Write(buf); err != nil || n != len(buf) {
+		t.Fatalf("Expected to write %d bytes, got %d and %v instead\n", len(buf), n, err)
+	}
+	copy(buf, "overwritten by the Logger")
+	w.Write([]byte("no key\n"))
+	w.Write([]byte("fail\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if len(p.messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d instead\n", len(p.messages))
+	}
+	first := p.messages[0]
+	if v, _ := first.Value.Encode(); first.Topic != "logs" || string(v) != "served request_id=abc" {
+		t.Errorf("Expected 'served request_id=abc' on logs, got %q on %s instead\n", v, first.Topic)
+	}
+	if k, _ := first.Key.Encode(); string(k) != "abc" {
+		t.Errorf("Expected the key to be abc, got %q instead\n", k)
+	}
+	if p.messages[1].Key != nil {
+		t.Errorf("Expected no key, got %v instead\n", p.messages[1].Key)
+	}
+	if fallback.String() != "fail\n" {
+		t.Errorf("Expected the failed line to be written to the fallback, got %q instead\n", fallback.String())
+	}
+	if _, err := w.Write([]byte("closed\n")); err != ErrClosed {
+		t.Errorf("Expected ErrClosed, got %v instead\n", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Package logprom counts log entries with Prometheus, so log volume can be graphed and alerted on
+// without scraping logs.
+package logprom
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/DramaFever/go-logging"
+)
+
+// Hook is a logging.Hook that counts every entry it sees in the log_messages_total counter, labelled
+// by level. A single Hook can be added to any number of Loggers.
+type Hook struct {
+	counter *prometheus.CounterVec
+}
+
+// NewHook returns a Hook and the unregistered counter it increments, for callers who want to register
+// the counter themselves.
+func NewHook() (*Hook, *prometheus.CounterVec) {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "log_messages_total",
+		Help: "Number of log messages written, by level.",
+	}, []string{"level"})
+	return &Hook{counter: counter}, counter
+}
+
+// Register returns a Hook whose counter is registered with reg. If reg already has a log_messages_total
+// counter, like one registered by an earlier call, the Hook uses that counter instead of registering a
+// second one.
+func Register(reg prometheus.Registerer) (*Hook, error) {
+	hook, counter := NewHook()
+	if err := reg.Register(counter); err != nil {
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return nil, err
+		}
+		existing, ok := are.ExistingCollector.(*prometheus.CounterVec)
+		if !ok {
+			return nil, err
+		}
+		hook.counter = existing
+	}
+	return hook, nil
+}
+
+var (
+	defaultHook     *Hook
+	defaultHookErr  error
+	defaultHookOnce sync.Once
+)
+
+// DefaultHook returns a Hook whose counter is registered with prometheus.DefaultRegisterer. The counter
+// is registered the first time DefaultHook is called, and every call returns the same Hook.
+func DefaultHook() (*Hook, error) {
+	defaultHookOnce.Do(func() {
+		defaultHook, defaultHookErr = Register(prometheus.DefaultRegisterer)
+	})
+	return defaultHook, defaultHookErr
+}
+
+// Fire increments the counter for e's level.
+func (h *Hook) Fire(e *logging.Entry) {
+	h.counter.WithLabelValues(string(e.Level)).Inc()
+}
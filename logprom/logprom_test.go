@@ -0,0 +1,60 @@
+package logprom
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/DramaFever/go-logging"
+)
+
+func TestHook(t *testing.T) {
+	hook, counter := NewHook()
+	for _, name := range []string{"one", "two"} {
+		log, err := logging.New(logging.InfoLvl, ioutil.Discard, "", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v\n", err)
+		}
+		log.AddHook(hook)
+		log.Debug(name)
+		log.Info(name)
+		log.Error(name)
+	}
+	if got := testutil.ToFloat64(counter.WithLabelValues("INFO")); got != 2 {
+		t.Errorf("Expected 2 INFO messages, got %v instead\n", got)
+	}
+	if got := testutil.ToFloat64(counter.WithLabelValues("ERROR")); got != 2 {
+		t.Errorf("Expected 2 ERROR messages, got %v instead\n", got)
+	}
+	if got := testutil.ToFloat64(counter.WithLabelValues("DEBUG")); got != 0 {
+		t.Errorf("Expected suppressed messages not to be counted, got %v instead\n", got)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	first, err := Register(reg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	second, err := Register(reg)
+	if err != nil {
+		t.Fatalf("Expected registering twice to reuse the counter, got %+v instead\n", err)
+	}
+	if first.counter != second.counter {
+		t.Error("Expected both Hooks to share a counter")
+	}
+}
+
+func TestDefaultHook(t *testing.T) {
+	first, err := DefaultHook()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	second, err := DefaultHook()
+	if err != nil || first != second {
+		t.Errorf("Expected the same Hook from every call, got %p and %p (%v) instead\n", first, second, err)
+	}
+}
@@ -0,0 +1,86 @@
+// Package logtest provides helpers for testing code that logs with the logging package.
+package logtest
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DramaFever/go-logging"
+)
+
+// Recorder captures every entry written by a Logger, so tests can make assertions about them without
+// parsing formatted output.
+type Recorder struct {
+	mu sync.Mutex
+
+	// Entries holds every entry recorded, in the order they were written.
+	Entries []logging.Entry
+}
+
+// NewRecorder returns a Logger set to DebugLvl that records its entries instead of writing them, and the
+// Recorder that holds them.
+func NewRecorder() (*logging.Logger, *Recorder) {
+	r := &Recorder{}
+	l, err := logging.NewWithOptions(
+		logging.WithLevel(logging.DebugLvl),
+		logging.WithOutput(ioutil.Discard),
+		logging.WithFormatter(r),
+	)
+	if err != nil {
+		panic(err.Error())
+	}
+	return l, r
+}
+
+// Format records e. It satisfies logging.Formatter, and writes nothing to buf.
+func (r *Recorder) Format(buf *[]byte, e *logging.Entry) {
+	entry := *e
+	entry.Message = string([]byte(e.Message))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Entries = append(r.Entries, entry)
+}
+
+// All returns a copy of the entries recorded so far.
+func (r *Recorder) All() []logging.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := make([]logging.Entry, len(r.Entries))
+	copy(entries, r.Entries)
+	return entries
+}
+
+// Reset discards all the entries recorded so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Entries = nil
+}
+
+// Logged reports whether an entry was recorded at lvl with a message containing substr.
+func (r *Recorder) Logged(lvl logging.Level, substr string) bool {
+	for _, e := range r.All() {
+		if e.Level == lvl && strings.Contains(e.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertLogged fails the test if no entry was recorded at lvl with a message containing substr.
+func (r *Recorder) AssertLogged(t testing.TB, lvl logging.Level, substr string) {
+	t.Helper()
+	if !r.Logged(lvl, substr) {
+		t.Errorf("Expected a %s entry containing '%s', got %+v instead\n", lvl, substr, r.All())
+	}
+}
+
+// AssertNotLogged fails the test if an entry was recorded at lvl with a message containing substr.
+func (r *Recorder) AssertNotLogged(t testing.TB, lvl logging.Level, substr string) {
+	t.Helper()
+	if r.Logged(lvl, substr) {
+		t.Errorf("Expected no %s entry containing '%s', got %+v instead\n", lvl, substr, r.All())
+	}
+}
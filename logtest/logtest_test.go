@@ -0,0 +1,35 @@
+package logtest
+
+import (
+	"testing"
+
+	"github.com/DramaFever/go-logging"
+)
+
+func TestRecorder(t *testing.T) {
+	log, recorder := NewRecorder()
+	log = log.SetLevel(logging.InfoLvl)
+	log.Debug("suppressed")
+	log.Child(logging.Fields{"user": 42}).Errorf("failed to load user %d", 42)
+	log.Info("loaded", "config")
+
+	if len(recorder.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d instead\n", len(recorder.Entries))
+	}
+	if recorder.Entries[0].Level != logging.ErrorLvl {
+		t.Errorf("Expected first entry to be %s, got %s instead\n", logging.ErrorLvl, recorder.Entries[0].Level)
+	}
+	if recorder.Entries[0].Fields["user"] != 42 {
+		t.Errorf("Expected first entry to have a user field, got %+v instead\n", recorder.Entries[0].Fields)
+	}
+	if recorder.Entries[1].Message != "loaded config" {
+		t.Errorf("Expected second entry to be 'loaded config', got '%s' instead\n", recorder.Entries[1].Message)
+	}
+	recorder.AssertLogged(t, logging.ErrorLvl, "failed to load user 42")
+	recorder.AssertNotLogged(t, logging.DebugLvl, "suppressed")
+
+	recorder.Reset()
+	if len(recorder.All()) != 0 {
+		t.Errorf("Expected no entries after Reset, got %+v\n", recorder.All())
+	}
+}
@@ -0,0 +1,32 @@
+package logtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DramaFever/go-logging"
+	"github.com/DramaFever/raven-go"
+)
+
+func TestSentryRecorder(t *testing.T) {
+	log, _ := NewRecorder()
+	sentry, err := NewSentryRecorder(log)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Info("not sent")
+	log.Warnf("retrying %s", "upstream")
+	log.Error("database unreachable")
+	if err := log.FlushSentry(time.Second); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if len(sentry.Packets) != 2 {
+		t.Fatalf("Expected 2 Sentry events, got %d instead\n", len(sentry.Packets))
+	}
+	sentry.AssertSent(t, raven.WARNING, "retrying upstream")
+	sentry.AssertSent(t, raven.ERROR, "database unreachable")
+	if sentry.Sent(raven.INFO, "not sent") {
+		t.Errorf("Expected %s entries not to be sent to Sentry\n", logging.InfoLvl)
+	}
+}
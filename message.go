@@ -0,0 +1,150 @@
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SetEscapeControl controls whether control characters in messages, like newlines and carriage returns,
+// are replaced with escape sequences (\n, \r, \t, or \xNN) before they are written. This keeps every
+// entry on one line, so an attacker who controls part of a message can't forge extra entries in
+// line-oriented logs. It is off by default for backward compatibility, but should be turned on whenever
+// messages may include untrusted input.
+func (l *Logger) SetEscapeControl(escape bool) *Logger {
+	l.escapeControl = escape
+	return l
+}
+
+// SetSanitizeUTF8 controls whether invalid UTF-8 in messages is replaced with the Unicode replacement
+// character, U+FFFD, before it is written, so JSON encoders and log viewers downstream don't reject the
+// entry. Turn it on when messages may include bytes from untrusted sources; it is off by default, since
+// checking every message has a cost that trusted input doesn't need to pay.
+func (l *Logger) SetSanitizeUTF8(sanitize bool) *Logger {
+	l.sanitizeUTF8 = sanitize
+	return l
+}
+
+// sanitizeUTF8 replaces each run of invalid UTF-8 in s with U+FFFD.
+func sanitizeUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	return strings.ToValidUTF8(s, "\uFFFD")
+}
+
+// SetContinuationPrefix sets a marker, like a tab or "  | ", that is written at the start of every line
+// after the first in a multi-line message, such as a stack trace. This keeps continuation lines visibly
+// attached to their entry, and lets them be told apart from new entries when parsing line by line. An
+// empty prefix, the default, leaves messages unchanged.
+func (l *Logger) SetContinuationPrefix(prefix string) *Logger {
+	l.continuationPrefix = prefix
+	return l
+}
+
+// SetPrefix sets a string written at the start of every message, after the header, like a service name
+// or shard ID that tells apart the entries of several programs merged into one stream. It is written
+// exactly as given, so it should usually end with a space or other separator, like "[shard-3] ". Unlike
+// a field, it is part of the message, so it appears the same way in every format. It is left out of the
+// events sent to Sentry, so they still group by message. An empty prefix, the default, leaves messages
+// unchanged.
+func (l *Logger) SetPrefix(prefix string) *Logger {
+	l.prefix = prefix
+	return l
+}
+
+// SetSeparator sets the string written between the arguments of the methods that take a list of
+// arguments rather than a format string, like Info and Debug. By default, arguments are joined as by
+// fmt.Sprintln, with a space between every pair of them, whatever their types. This differs from
+// fmt.Sprint, which only adds a space between two arguments when neither is a string: Info("a", "b")
+// writes "a b", where fmt.Sprint would give "ab". With a separator set, each argument is formatted as by
+// fmt.Sprint and joined with sep, so SetSeparator("") joins them with nothing at all, and
+// SetSeparator(", ") with a comma and a space.
+func (l *Logger) SetSeparator(sep string) *Logger {
+	l.separator = sep
+	l.separatorSet = true
+	return l
+}
+
+// join joins the arguments of a method without a format string into a message, with the Logger's
+// separator, or as by fmt.Sprintln if it has none. The trailing newline may be left out.
+func (l *Logger) join(msg ...interface{}) string {
+	if !l.separatorSet {
+		return sprintln(msg...)
+	}
+	var b strings.Builder
+	for i, m := range msg {
+		if i > 0 {
+			b.WriteString(l.separator)
+		}
+		fmt.Fprint(&b, m)
+	}
+	return b.String()
+}
+
+// joinln is join, always with a trailing newline, for the messages sent to Sentry.
+func (l *Logger) joinln(msg ...interface{}) string {
+	if !l.separatorSet {
+		return fmt.Sprintln(msg...)
+	}
+	return l.join(msg...) + "\n"
+}
+
+// indentContinuations writes prefix after every newline in s.
+func indentContinuations(s, prefix string) string {
+	if prefix == "" || strings.IndexByte(s, '\n') < 0 {
+		return s
+	}
+	return strings.Replace(s, "\n", "\n"+prefix, -1)
+}
+
+// isControl reports whether b is an ASCII control character.
+func isControl(b byte) bool {
+	return b < ' ' || b == 0x7f
+}
+
+// escapeControl replaces the ASCII control characters in s with escape sequences.
+func escapeControl(s string) string {
+	if strings.IndexFunc(s, func(r rune) bool { return r < utf8.RuneSelf && isControl(byte(r)) }) < 0 {
+		return s
+	}
+	const hex = "0123456789abcdef"
+	escaped := make([]byte, 0, len(s)+8)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\n':
+			escaped = append(escaped, '\\', 'n')
+		case c == '\r':
+			escaped = append(escaped, '\\', 'r')
+		case c == '\t':
+			escaped = append(escaped, '\\', 't')
+		case isControl(c):
+			escaped = append(escaped, '\\', 'x', hex[c>>4], hex[c&0xf])
+		default:
+			escaped = append(escaped, c)
+		}
+	}
+	return string(escaped)
+}
+
+// SetMaxMessageLength limits messages to n bytes. Longer messages are cut at the last full UTF-8
+// character that fits, and a suffix noting how many bytes were omitted is appended. Fields and the header
+// are not counted. A limit of 0 or less, the default, disables truncation.
+func (l *Logger) SetMaxMessageLength(n int) *Logger {
+	l.maxMessageLength = n
+	return l
+}
+
+// truncate shortens s to at most max bytes, without splitting a UTF-8 sequence.
+func truncate(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…(truncated, " + strconv.Itoa(len(s)-cut) + " bytes omitted)"
+}
@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	type truncateTest struct {
+		in  string
+		max int
+		out string
+	}
+	truncateTests := []truncateTest{
+		{in: "short", max: 0, out: "short"},
+		{in: "short", max: 5, out: "short"},
+		{in: "longer message", max: 6, out: "longer…(truncated, 8 bytes omitted)"},
+		// "é" is two bytes, and mustn't be split
+		{in: "caféine", max: 4, out: "caf…(truncated, 5 bytes omitted)"},
+		{in: "caféine", max: 5, out: "café…(truncated, 3 bytes omitted)"},
+	}
+	for _, test := range truncateTests {
+		result := truncate(test.in, test.max)
+		if result != test.out {
+			t.Errorf("Expected '%s' truncated to %d to be '%s', got '%s' instead\n", test.in, test.max, test.out, result)
+		}
+	}
+}
+
+func TestEscapeControl(t *testing.T) {
+	escapeTests := map[string]string{
+		"plain message":                        "plain message",
+		"café":                                 "café",
+		"line one\nline two":                   `line one\nline two`,
+		"forged\r\n2015-07-02T13:28:42 [INFO]": `forged\r\n2015-07-02T13:28:42 [INFO]`,
+		"tab\tbell\adel\x7f":                   `tab\tbell\x07del\x7f`,
+	}
+	for in, out := range escapeTests {
+		result := escapeControl(in)
+		if result != out {
+			t.Errorf("Expected '%s' to be escaped to '%s', got '%s' instead\n", in, out, result)
+		}
+	}
+
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetEscapeControl(true).Info("user said: hi\nERROR fake entry")
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("Expected a single line, got '%s'\n", buf.String())
+	}
+}
+
+func TestSanitizeUTF8(t *testing.T) {
+	sanitizeTests := map[string]string{
+		"plain message":      "plain message",
+		"café":               "café",
+		"bad \xff byte":      "bad \uFFFD byte",
+		"bad \xc3\x28 run":   "bad \uFFFD( run",
+		"truncated \xe2\x82": "truncated \uFFFD",
+	}
+	for in, out := range sanitizeTests {
+		result := sanitizeUTF8(in)
+		if result != out {
+			t.Errorf("Expected %q to be sanitized to %q, got %q instead\n", in, out, result)
+		}
+	}
+
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Info("raw \xff")
+	if !strings.HasSuffix(buf.String(), "raw \xff\n") {
+		t.Errorf("Expected the message to be written unchanged, got %q instead\n", buf.String())
+	}
+	buf.Reset()
+	log.SetSanitizeUTF8(true).Info("raw \xff")
+	if !strings.HasSuffix(buf.String(), "raw \uFFFD\n") {
+		t.Errorf("Expected invalid UTF-8 to be replaced, got %q instead\n", buf.String())
+	}
+}
+
+func TestSetContinuationPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.SetContinuationPrefix("\t| ")
+	log.Error("panic: oops\ngoroutine 1 [running]:\nmain.main()")
+	expected := ": panic: oops\n\t| goroutine 1 [running]:\n\t| main.main()\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+
+	buf.Reset()
+	log.Info("single line")
+	if !strings.HasSuffix(buf.String(), ": single line\n") {
+		t.Errorf("Expected single line messages to be unchanged, got '%s'\n", buf.String())
+	}
+}
+
+func TestSetPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithPrefix("[shard-3] "))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Info("started")
+	if !strings.HasSuffix(buf.String(), ": [shard-3] started\n") {
+		t.Errorf("Expected the prefix before the message, got '%s' instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log.SetPrefix("").Info("started")
+	if !strings.HasSuffix(buf.String(), ": started\n") {
+		t.Errorf("Expected no prefix, got '%s' instead\n", buf.String())
+	}
+}
+
+func TestSetSeparator(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	tests := []struct {
+		set      bool
+		sep      string
+		expected string
+	}{
+		{false, "", ": a b 1 true\n"},
+		{true, ", ", ": a, b, 1, true\n"},
+		{true, "", ": ab1true\n"},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		if test.set {
+			log.SetSeparator(test.sep)
+		}
+		log.Info("a", "b", 1, true)
+		if !strings.HasSuffix(buf.String(), test.expected) {
+			t.Errorf("Expected output to end with '%s', got '%s' instead\n", test.expected, buf.String())
+		}
+	}
+}
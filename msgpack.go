@@ -0,0 +1,177 @@
+package logging
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// These append MessagePack encodings to a buffer, for the Fluentd Forward protocol. Each uses the most
+// compact encoding the MessagePack spec allows for its value.
+
+// appendUint32 appends v to buf in big-endian order. It is binary.BigEndian.AppendUint32, which needs
+// Go 1.19.
+func appendUint32(buf *[]byte, v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	*buf = append(*buf, b[:]...)
+}
+
+// appendUint64 appends v to buf in big-endian order. It is binary.BigEndian.AppendUint64, which needs
+// Go 1.19.
+func appendUint64(buf *[]byte, v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	*buf = append(*buf, b[:]...)
+}
+
+// appendMsgpackArrayHeader appends the header of an array of n elements to buf.
+func appendMsgpackArrayHeader(buf *[]byte, n int) {
+	switch {
+	case n < 16:
+		*buf = append(*buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		*buf = append(*buf, 0xdc, byte(n>>8), byte(n))
+	default:
+		*buf = append(*buf, 0xdd)
+		appendUint32(buf, uint32(n))
+	}
+}
+
+// appendMsgpackMapHeader appends the header of a map of n key/value pairs to buf.
+func appendMsgpackMapHeader(buf *[]byte, n int) {
+	switch {
+	case n < 16:
+		*buf = append(*buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		*buf = append(*buf, 0xde, byte(n>>8), byte(n))
+	default:
+		*buf = append(*buf, 0xdf)
+		appendUint32(buf, uint32(n))
+	}
+}
+
+// appendMsgpackString appends s to buf as a MessagePack string.
+func appendMsgpackString(buf *[]byte, s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		*buf = append(*buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		*buf = append(*buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		*buf = append(*buf, 0xda, byte(n>>8), byte(n))
+	default:
+		*buf = append(*buf, 0xdb)
+		appendUint32(buf, uint32(n))
+	}
+	*buf = append(*buf, s...)
+}
+
+// appendMsgpackBinHeader appends the header of n bytes of binary data to buf.
+func appendMsgpackBinHeader(buf *[]byte, n int) {
+	switch {
+	case n <= math.MaxUint8:
+		*buf = append(*buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		*buf = append(*buf, 0xc5, byte(n>>8), byte(n))
+	default:
+		*buf = append(*buf, 0xc6)
+		appendUint32(buf, uint32(n))
+	}
+}
+
+// appendMsgpackInt appends i to buf as a MessagePack integer.
+func appendMsgpackInt(buf *[]byte, i int64) {
+	switch {
+	case i >= 0:
+		appendMsgpackUint(buf, uint64(i))
+	case i >= -32:
+		*buf = append(*buf, byte(i))
+	case i >= math.MinInt8:
+		*buf = append(*buf, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		*buf = append(*buf, 0xd1, byte(i>>8), byte(i))
+	case i >= math.MinInt32:
+		*buf = append(*buf, 0xd2)
+		appendUint32(buf, uint32(i))
+	default:
+		*buf = append(*buf, 0xd3)
+		appendUint64(buf, uint64(i))
+	}
+}
+
+// appendMsgpackUint appends u to buf as a MessagePack integer.
+func appendMsgpackUint(buf *[]byte, u uint64) {
+	switch {
+	case u < 128:
+		*buf = append(*buf, byte(u))
+	case u <= math.MaxUint8:
+		*buf = append(*buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		*buf = append(*buf, 0xcd, byte(u>>8), byte(u))
+	case u <= math.MaxUint32:
+		*buf = append(*buf, 0xce)
+		appendUint32(buf, uint32(u))
+	default:
+		*buf = append(*buf, 0xcf)
+		appendUint64(buf, u)
+	}
+}
+
+// appendMsgpackEventTime appends t to buf as a Fluentd EventTime, an extension type holding seconds and
+// nanoseconds since the Unix epoch.
+func appendMsgpackEventTime(buf *[]byte, t time.Time) {
+	*buf = append(*buf, 0xd7, 0x00)
+	appendUint32(buf, uint32(t.Unix()))
+	appendUint32(buf, uint32(t.Nanosecond()))
+}
+
+// appendMsgpackValue appends v to buf as a MessagePack number, boolean, or nil if it is one, or as a
+// string otherwise.
+func appendMsgpackValue(buf *[]byte, v interface{}) {
+	EncodeValue(msgpackEncoder{}, buf, v)
+}
+
+// msgpackEncoder is the Encoder used by the FluentFormatter. It writes each field as a pair of MessagePack
+// values in a map, and groups as nested maps.
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) AppendKey(buf *[]byte, key string) { appendMsgpackString(buf, key) }
+
+func (enc msgpackEncoder) AppendGroup(buf *[]byte, key string, group Fields) {
+	appendMsgpackString(buf, key)
+	appendMsgpackMapHeader(buf, len(group))
+	EncodeFields(enc, buf, group)
+}
+
+func (msgpackEncoder) AppendString(buf *[]byte, s string) { appendMsgpackString(buf, s) }
+func (msgpackEncoder) AppendInt(buf *[]byte, i int64)     { appendMsgpackInt(buf, i) }
+func (msgpackEncoder) AppendUint(buf *[]byte, u uint64)   { appendMsgpackUint(buf, u) }
+func (msgpackEncoder) AppendNil(buf *[]byte)              { *buf = append(*buf, 0xc0) }
+
+func (msgpackEncoder) AppendFloat(buf *[]byte, f float64, bitSize int) {
+	if bitSize == 32 {
+		*buf = append(*buf, 0xca)
+		appendUint32(buf, math.Float32bits(float32(f)))
+		return
+	}
+	*buf = append(*buf, 0xcb)
+	appendUint64(buf, math.Float64bits(f))
+}
+
+func (msgpackEncoder) AppendBool(buf *[]byte, b bool) {
+	if b {
+		*buf = append(*buf, 0xc3)
+	} else {
+		*buf = append(*buf, 0xc2)
+	}
+}
+
+func (msgpackEncoder) AppendTime(buf *[]byte, t time.Time) {
+	appendMsgpackString(buf, t.Format(time.RFC3339Nano))
+}
+
+func (msgpackEncoder) AppendDuration(buf *[]byte, d time.Duration) {
+	appendMsgpackString(buf, d.String())
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAppendMsgpackValue(t *testing.T) {
+	valueTests := []struct {
+		in       interface{}
+		expected []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{false, []byte{0xc2}},
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{200, []byte{0xcc, 0xc8}},
+		{uint16(65535), []byte{0xcd, 0xff, 0xff}},
+		{70000, []byte{0xce, 0x00, 0x01, 0x11, 0x70}},
+		{int64(1) << 40, []byte{0xcf, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{-1, []byte{0xff}},
+		{-32, []byte{0xe0}},
+		{-33, []byte{0xd0, 0xdf}},
+		{-200, []byte{0xd1, 0xff, 0x38}},
+		{-70000, []byte{0xd2, 0xff, 0xfe, 0xee, 0x90}},
+		{int64(-1) << 40, []byte{0xd3, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{float32(1.5), []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}},
+		{1.5, []byte{0xcb, 0x3f, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"hi", []byte{0xa2, 'h', 'i'}},
+		{errors.New("no"), []byte{0xa2, 'n', 'o'}},
+		{[]int{1}, []byte{0xa3, '[', '1', ']'}},
+	}
+	for _, test := range valueTests {
+		var buf []byte
+		appendMsgpackValue(&buf, test.in)
+		if !bytes.Equal(buf, test.expected) {
+			t.Errorf("Expected %#v to be encoded as % x, got % x instead\n", test.in, test.expected, buf)
+		}
+	}
+}
+
+func TestAppendMsgpackHeaders(t *testing.T) {
+	headerTests := []struct {
+		append   func(*[]byte, int)
+		n        int
+		expected []byte
+	}{
+		{appendMsgpackArrayHeader, 2, []byte{0x92}},
+		{appendMsgpackArrayHeader, 16, []byte{0xdc, 0x00, 0x10}},
+		{appendMsgpackArrayHeader, 1 << 16, []byte{0xdd, 0x00, 0x01, 0x00, 0x00}},
+		{appendMsgpackMapHeader, 3, []byte{0x83}},
+		{appendMsgpackMapHeader, 16, []byte{0xde, 0x00, 0x10}},
+		{appendMsgpackBinHeader, 5, []byte{0xc4, 0x05}},
+		{appendMsgpackBinHeader, 256, []byte{0xc5, 0x01, 0x00}},
+		{appendMsgpackBinHeader, 1 << 16, []byte{0xc6, 0x00, 0x01, 0x00, 0x00}},
+	}
+	for _, test := range headerTests {
+		var buf []byte
+		test.append(&buf, test.n)
+		if !bytes.Equal(buf, test.expected) {
+			t.Errorf("Expected a header for %d to be % x, got % x instead\n", test.n, test.expected, buf)
+		}
+	}
+
+	for n, prefix := range map[int][]byte{
+		31:      {0xbf},
+		32:      {0xd9, 0x20},
+		256:     {0xda, 0x01, 0x00},
+		1 << 16: {0xdb, 0x00, 0x01, 0x00, 0x00},
+	} {
+		var buf []byte
+		appendMsgpackString(&buf, strings.Repeat("x", n))
+		if !bytes.HasPrefix(buf, prefix) || len(buf) != len(prefix)+n {
+			t.Errorf("Expected a %d byte string to start with % x, got % x instead\n", n, prefix, buf[:len(prefix)])
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package logging
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// netWriterMinBackoff is how long a NetWriter waits before its first attempt to reconnect.
+	netWriterMinBackoff = 100 * time.Millisecond
+	// netWriterMaxBackoff is the longest a NetWriter waits between attempts to reconnect.
+	netWriterMaxBackoff = 30 * time.Second
+	// netWriterMaxPending is the most a NetWriter holds on to while it is disconnected.
+	netWriterMaxPending = 1 << 20
+	// netWriterTimeout is the longest a NetWriter waits to connect, or for a write to complete, before it
+	// treats the collector as unreachable. Writes happen while the Logger holds its lock, so a collector
+	// that stops responding must not stall every goroutine that logs.
+	netWriterTimeout = 5 * time.Second
+)
+
+// ErrNetWriterBufferFull is returned by a NetWriter when it has been disconnected for long enough that
+// its buffer is full. The data passed to that Write is dropped.
+var ErrNetWriterBufferFull = errors.New("network log buffer is full; dropping output")
+
+// ErrNetWriterClosed is returned when writing to a NetWriter that has been closed.
+var ErrNetWriterClosed = errors.New("network log writer is closed")
+
+// ErrNetWriterDisconnected is returned by a NetWriter's Flush and Close methods when it holds output it
+// could not send, because it is waiting to reconnect.
+var ErrNetWriterDisconnected = errors.New("network log writer is disconnected; output was not sent")
+
+// NetWriter is an io.WriteCloser that sends log output to a remote collector over TCP or UDP. It can be
+// passed as the output to New. It is safe for concurrent use.
+//
+// Over TCP, a NetWriter that loses its connection buffers what is written to it, up to 1MB, and
+// reconnects with exponential backoff, sending the buffer once the connection is back. Over UDP, each
+// Write is sent as a single datagram, and nothing is buffered. Connecting and writing each time out after
+// 5 seconds, so an unresponsive collector is treated like a lost connection, rather than blocking the
+// Logger.
+type NetWriter struct {
+	network string
+	addr    string
+	dial    func(network, addr string) (net.Conn, error)
+	now     func() time.Time
+
+	mu      sync.Mutex
+	conn    net.Conn
+	pending []byte
+	backoff time.Duration
+	retryAt time.Time
+	closed  bool
+}
+
+// NewNetWriter connects to addr, a host:port, over network, which must be "tcp" or "udp" (or one of
+// their "4" and "6" variants), and returns a NetWriter that writes to it. It returns an error if the
+// first connection can't be made.
+func NewNetWriter(network, addr string) (*NetWriter, error) {
+	w := &NetWriter{
+		network: network,
+		addr:    addr,
+		dial:    dialTimeout,
+		now:     time.Now,
+	}
+	conn, err := w.dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	w.conn = conn
+	return w, nil
+}
+
+// dialTimeout connects to addr over network, giving up after netWriterTimeout.
+func dialTimeout(network, addr string) (net.Conn, error) {
+	return net.DialTimeout(network, addr, netWriterTimeout)
+}
+
+// WritesEntries reports whether each Write is sent as a separate message, which it is over UDP. See
+// EntryWriter.
+func (w *NetWriter) WritesEntries() bool {
+	return strings.HasPrefix(w.network, "udp")
+}
+
+// Write sends p to the collector, or buffers it if a TCP connection is down.
+func (w *NetWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrNetWriterClosed
+	}
+	if strings.HasPrefix(w.network, "udp") {
+		return w.write(p)
+	}
+	if len(w.pending)+len(p) > netWriterMaxPending {
+		w.flush()
+		if len(w.pending)+len(p) > netWriterMaxPending {
+			return 0, ErrNetWriterBufferFull
+		}
+	}
+	w.pending = append(w.pending, p...)
+	w.flush()
+	return len(p), nil
+}
+
+// Flush tries to send anything buffered while the connection was down. It returns an error if the
+// connection could not be restored, or ErrNetWriterDisconnected if it is still waiting to try again.
+func (w *NetWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	return w.flush()
+}
+
+// Close sends anything still buffered, if it can, and closes the connection. It returns an error if
+// buffered output could not be sent, which is then dropped.
+func (w *NetWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	err := w.flush()
+	w.closed = true
+	w.pending = nil
+	if w.conn == nil {
+		return err
+	}
+	return combineErrors(err, w.conn.Close())
+}
+
+// flush writes w.pending to the connection, reconnecting first if the connection is down and the
+// backoff has passed. Whatever can't be written stays in w.pending. The caller must hold w.mu.
+func (w *NetWriter) flush() error {
+	if w.conn == nil {
+		if w.now().Before(w.retryAt) {
+			if len(w.pending) > 0 {
+				return ErrNetWriterDisconnected
+			}
+			return nil
+		}
+		conn, err := w.dial(w.network, w.addr)
+		if err != nil {
+			w.disconnected()
+			return err
+		}
+		w.conn = conn
+		w.backoff = 0
+	}
+	if len(w.pending) == 0 {
+		return nil
+	}
+	n, err := w.write(w.pending)
+	w.pending = w.pending[:copy(w.pending, w.pending[n:])]
+	if err != nil {
+		w.conn.Close()
+		w.conn = nil
+		w.disconnected()
+		return err
+	}
+	return nil
+}
+
+// write writes p to the connection, giving up after netWriterTimeout. The caller must hold w.mu.
+func (w *NetWriter) write(p []byte) (int, error) {
+	if err := w.conn.SetWriteDeadline(time.Now().Add(netWriterTimeout)); err != nil {
+		return 0, err
+	}
+	return w.conn.Write(p)
+}
+
+// disconnected doubles the backoff, up to netWriterMaxBackoff, and schedules the next reconnection
+// attempt. The caller must hold w.mu.
+func (w *NetWriter) disconnected() {
+	w.backoff *= 2
+	if w.backoff < netWriterMinBackoff {
+		w.backoff = netWriterMinBackoff
+	}
+	if w.backoff > netWriterMaxBackoff {
+		w.backoff = netWriterMaxBackoff
+	}
+	w.retryAt = w.now().Add(w.backoff)
+}
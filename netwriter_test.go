@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNetWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer ln.Close()
+	w, err := NewNetWriter("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log, err := New(InfoLvl, w, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer log.Close()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer conn.Close()
+	log.Info("over the wire")
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if !strings.HasSuffix(line, "over the wire\n") {
+		t.Errorf("Expected line to end with 'over the wire', got '%s' instead\n", line)
+	}
+}
+
+func TestNetWriterUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer pc.Close()
+	w, err := NewNetWriter("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte("datagram\n")); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	buf := make([]byte, 64)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if string(buf[:n]) != "datagram\n" {
+		t.Errorf("Expected 'datagram\\n', got '%s' instead\n", buf[:n])
+	}
+}
+
+// fakeConn is a net.Conn that records what is written to it, or fails every write once broken.
+type fakeConn struct {
+	net.Conn
+	written []byte
+	broken  bool
+}
+
+func (c *fakeConn) Write(p []byte) (int, error) {
+	if c.broken {
+		return 0, errors.New("broken pipe")
+	}
+	c.written = append(c.written, p...)
+	return len(p), nil
+}
+
+func (c *fakeConn) Close() error                       { return nil }
+func (c *fakeConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func TestNetWriterReconnect(t *testing.T) {
+	now := time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC)
+	first := &fakeConn{broken: true}
+	second := &fakeConn{}
+	dials := 0
+	w := &NetWriter{
+		network: "tcp",
+		conn:    first,
+		now:     func() time.Time { return now },
+		dial: func(network, addr string) (net.Conn, error) {
+			dials++
+			if dials == 1 {
+				return nil, errors.New("connection refused")
+			}
+			return second, nil
+		},
+	}
+
+	w.Write([]byte("one\n"))
+	if w.conn != nil || w.backoff != netWriterMinBackoff {
+		t.Fatalf("Expected to be disconnected with a backoff of %s, got %s instead\n", netWriterMinBackoff, w.backoff)
+	}
+	w.Write([]byte("two\n"))
+	if dials != 0 {
+		t.Errorf("Expected no reconnection before the backoff passed, got %d instead\n", dials)
+	}
+
+	now = now.Add(netWriterMinBackoff)
+	if err := w.Flush(); err == nil {
+		t.Error("Expected an error from the failed reconnection")
+	}
+	if w.backoff != 2*netWriterMinBackoff {
+		t.Errorf("Expected backoff to double to %s, got %s instead\n", 2*netWriterMinBackoff, w.backoff)
+	}
+
+	now = now.Add(2 * netWriterMinBackoff)
+	w.Write([]byte("three\n"))
+	if string(second.written) != "one\ntwo\nthree\n" {
+		t.Errorf("Expected buffered lines to be sent after reconnecting, got '%s' instead\n", second.written)
+	}
+	if w.backoff != 0 {
+		t.Errorf("Expected backoff to reset, got %s instead\n", w.backoff)
+	}
+
+	second.broken = true
+	w.Write([]byte("four\n"))
+	if err := w.Flush(); err != ErrNetWriterDisconnected {
+		t.Errorf("Expected %v during the backoff, got %v instead\n", ErrNetWriterDisconnected, err)
+	}
+	if err := w.Close(); err != ErrNetWriterDisconnected {
+		t.Errorf("Expected %v closing with undelivered output, got %v instead\n", ErrNetWriterDisconnected, err)
+	}
+}
+
+func TestNetWriterClosed(t *testing.T) {
+	w := &NetWriter{network: "tcp", conn: &fakeConn{}, now: time.Now}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if _, err := w.Write([]byte("late\n")); err != ErrNetWriterClosed {
+		t.Errorf("Expected %v, got %v instead\n", ErrNetWriterClosed, err)
+	}
+}
@@ -0,0 +1,182 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// Option configures a Logger created by NewWithOptions.
+type Option func(*Logger) error
+
+// WithLevel sets the Level of the Logger. Loggers default to InfoLvl.
+func WithLevel(level Level) Option {
+	return func(l *Logger) error {
+		l.setLevel(level)
+		return nil
+	}
+}
+
+// WithOutput sets the io.Writer the Logger writes to. Loggers default to stderr. If the io.Writer is an
+// io.WriteCloser, it will be automatically closed when the Logger's Close method is called. A nil
+// io.Writer is rejected with ErrNilWriter, since it would silently swallow every entry; pass Discard to
+// throw output away on purpose.
+func WithOutput(out io.Writer) Option {
+	return func(l *Logger) error {
+		if out == nil {
+			return ErrNilWriter
+		}
+		l.setOutput(out)
+		return nil
+	}
+}
+
+// WithSinks makes the Logger write every entry to each of the Sinks, in that Sink's format, instead of to
+// a single output. See SetSinks for details.
+func WithSinks(s ...Sink) Option {
+	return func(l *Logger) error {
+		for _, sink := range s {
+			if sink.Writer == nil {
+				return ErrNilWriter
+			}
+		}
+		l.setOutput(append(sinks(nil), s...))
+		return nil
+	}
+}
+
+// WithSentry uses dsn to connect to a Sentry error collector. The tags are a key/value mapping that will
+// be applied to your Sentry errors. If dsn is empty, Sentry is not configured.
+func WithSentry(dsn string, tags map[string]string) Option {
+	return func(l *Logger) error {
+		if dsn == "" {
+			return nil
+		}
+		client, err := newSentryClient(dsn)
+		l.sentry = client
+		l.sentryTags = newSentryTagSet(tags)
+		l.configureSentry()
+		return err
+	}
+}
+
+// WithSentryEnvironment sets the environment reported with Sentry events. See SetSentryEnvironment for
+// details. It can be passed before or after WithSentry.
+func WithSentryEnvironment(environment string) Option {
+	return func(l *Logger) error {
+		l.SetSentryEnvironment(environment)
+		return nil
+	}
+}
+
+// WithSentryRelease sets the release reported with Sentry events. See SetSentryRelease for details. It
+// can be passed before or after WithSentry.
+func WithSentryRelease(release string) Option {
+	return func(l *Logger) error {
+		l.SetSentryRelease(release)
+		return nil
+	}
+}
+
+// WithUTC makes the Logger convert timestamps to UTC before formatting them, instead of using the
+// local time zone.
+func WithUTC() Option {
+	return func(l *Logger) error {
+		l.utc = true
+		return nil
+	}
+}
+
+// WithFormatter sets the Formatter used to render each log entry. Loggers default to a TextFormatter.
+func WithFormatter(f Formatter) Option {
+	return func(l *Logger) error {
+		l.formatter = f
+		return nil
+	}
+}
+
+// WithCallDepth sets the call depth of the Logger. See SetCallDepth for details.
+func WithCallDepth(depth int) Option {
+	return func(l *Logger) error {
+		l.calldepth = depth
+		return nil
+	}
+}
+
+// WithPackagePrefixes sets the package prefixes used to flag stacktrace lines as "in app" in Sentry. See
+// SetPackagePrefixes for details.
+func WithPackagePrefixes(prefixes []string) Option {
+	return func(l *Logger) error {
+		l.packagePrefixes = prefixes
+		return nil
+	}
+}
+
+// WithErrorHandler sets the function called when the Logger fails to write to its output. See
+// SetErrorHandler for details.
+func WithErrorHandler(handler func(error)) Option {
+	return func(l *Logger) error {
+		l.errorHandler = handler
+		return nil
+	}
+}
+
+// WithClock sets the function the Logger uses to get the current time. See SetClock for details.
+func WithClock(now func() time.Time) Option {
+	return func(l *Logger) error {
+		l.now = now
+		return nil
+	}
+}
+
+// WithHostname adds a "hostname" field to every entry, holding the name of the host as reported by
+// os.Hostname. The name is looked up once, when the Logger is created.
+func WithHostname() Option {
+	return func(l *Logger) error {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		l.fields = l.fields.merge(Fields{"hostname": hostname})
+		return nil
+	}
+}
+
+// WithPID adds a "pid" field to every entry, holding the ID of the current process.
+func WithPID() Option {
+	return func(l *Logger) error {
+		l.fields = l.fields.merge(Fields{"pid": os.Getpid()})
+		return nil
+	}
+}
+
+// WithLineTerminator sets the string written after every entry. See SetLineTerminator for details.
+func WithLineTerminator(terminator string) Option {
+	return func(l *Logger) error {
+		l.lineTerminator = terminator
+		return nil
+	}
+}
+
+// WithPrefix sets a string written at the start of every message. See SetPrefix for details.
+func WithPrefix(prefix string) Option {
+	return func(l *Logger) error {
+		l.prefix = prefix
+		return nil
+	}
+}
+
+// WithStackdriver makes the Logger write entries in Google Cloud Logging's structured format. See
+// StackdriverFormatter for details.
+func WithStackdriver() Option {
+	return WithFormatter(StackdriverFormatter{})
+}
+
+// WithRFC5424 makes the Logger write entries as RFC 5424 syslog messages with the given facility and app
+// name. See NewRFC5424Formatter for details.
+func WithRFC5424(facility Facility, appName string) Option {
+	return func(l *Logger) error {
+		l.formatter = NewRFC5424Formatter(facility, appName)
+		return nil
+	}
+}
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+type upperFormatter struct{}
+
+func (f upperFormatter) Format(buf *[]byte, e *Entry) {
+	*buf = append(*buf, string(e.Level)+": "+e.Message...)
+}
+
+func TestNewWithOptions(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithLevel(WarnLvl), WithOutput(&buf), WithFormatter(upperFormatter{}), WithUTC())
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if log.GetLevel() != WarnLvl {
+		t.Errorf("Expected level to be %s, got %s instead\n", WarnLvl, log.GetLevel())
+	}
+	if !log.utc {
+		t.Error("Expected UTC to be enabled")
+	}
+	log.Info("suppressed")
+	log.Warn("Test", "output")
+	if buf.String() != "WARN: Test output\n" {
+		t.Errorf("Expected output to be '%s', got '%s' instead\n", "WARN: Test output\n", buf.String())
+	}
+}
+
+func TestNewWithOptionsDefaults(t *testing.T) {
+	log, err := NewWithOptions()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if log.GetLevel() != InfoLvl {
+		t.Errorf("Expected level to be %s, got %s instead\n", InfoLvl, log.GetLevel())
+	}
+	if log.enabled(DebugLvl) || !log.enabled(InfoLvl) {
+		t.Errorf("Expected the Logger to include %s but not %s\n", InfoLvl, DebugLvl)
+	}
+	if _, ok := log.formatter.(TextFormatter); !ok {
+		t.Errorf("Expected a TextFormatter, got %T instead\n", log.formatter)
+	}
+	if log.sentry != nil {
+		t.Error("Expected Sentry to be unconfigured")
+	}
+}
+
+func TestSetAlignLevels(t *testing.T) {
+	log, err := NewWithOptions(WithFormatter(upperFormatter{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if _, ok := log.SetAlignLevels(true).formatter.(upperFormatter); !ok {
+		t.Error("Expected a custom Formatter to be left alone")
+	}
+	log, err = NewWithOptions()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	clone := log.Clone()
+	log.SetAlignLevels(true)
+	if !log.formatter.(TextFormatter).AlignLevels {
+		t.Error("Expected levels to be aligned")
+	}
+	if clone.formatter.(TextFormatter).AlignLevels {
+		t.Error("Expected the clone to be unchanged")
+	}
+}
+
+func TestWithHostnameAndPID(t *testing.T) {
+	log, err := NewWithOptions(WithHostname(), WithPID())
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	hostname, _ := os.Hostname()
+	fields := log.GetFields()
+	if fields["hostname"] != hostname {
+		t.Errorf("Expected hostname field to be %s, got %v instead\n", hostname, fields["hostname"])
+	}
+	if fields["pid"] != os.Getpid() {
+		t.Errorf("Expected pid field to be %d, got %v instead\n", os.Getpid(), fields["pid"])
+	}
+}
+
+func TestNilWriter(t *testing.T) {
+	if _, err := New(InfoLvl, nil, "", nil); err != ErrNilWriter {
+		t.Errorf("Expected %+v, got %+v instead\n", ErrNilWriter, err)
+	}
+	if _, err := NewWithOptions(WithOutput(nil)); err != ErrNilWriter {
+		t.Errorf("Expected %+v, got %+v instead\n", ErrNilWriter, err)
+	}
+	log, err := New(InfoLvl, Discard, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log.Info("thrown away")
+}
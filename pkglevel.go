@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// packageLevel is a Level that applies to calls from the package at prefix.
+type packageLevel struct {
+	prefix string
+	level  Level
+}
+
+// SetPackageLevel sets the Level used for entries logged from the package pkgPrefix, like
+// "github.com/DramaFever/myapp/storage", and the packages beneath it, overriding the Logger's Level.
+// This lets one package log at DebugLvl while the rest of the program stays at InfoLvl, without a
+// separate Logger. When several prefixes match, the longest wins. The package is found from the path of
+// the caller's file, so it only works when source paths include import paths, as they do in GOPATH and
+// module builds without -trimpath.
+//
+// Package levels have a cost: while any are set, calls at Levels the Logger would normally suppress
+// can't be skipped by the cheap Level check alone, since they may come from a package that includes them.
+// They must look up their caller, and take the Logger's lock, before they are dropped. Package levels
+// only filter what is written to the output; WarnLvl and ErrorLvl events are still sent to Sentry
+// according to the Logger's own Level.
+func (l *Logger) SetPackageLevel(pkgPrefix string, level Level) *Logger {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	levels := make([]packageLevel, 0, len(l.packageLevels)+1)
+	for _, p := range l.packageLevels {
+		if p.prefix != pkgPrefix {
+			levels = append(levels, p)
+		}
+	}
+	levels = append(levels, packageLevel{prefix: pkgPrefix, level: level})
+	sort.SliceStable(levels, func(i, j int) bool {
+		return len(levels[i].prefix) > len(levels[j].prefix)
+	})
+	l.packageLevels = levels
+	atomic.StoreInt32(&l.packageLeveled, 1)
+	l.setLevel(l.level)
+	return l
+}
+
+// packageIncludes reports whether an entry logged at lvl from file should be written, according to the
+// Logger's package levels, or its Level if none match.
+func (l *Logger) packageIncludes(file string, lvl Level) bool {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	for _, p := range l.packageLevels {
+		if inPackage(file, p.prefix) {
+			return p.level.includes(lvl)
+		}
+	}
+	return l.level.includes(lvl)
+}
+
+// lowestThreshold returns the lowest threshold of lvl and the Logger's package levels, which is the
+// least severity an entry needs to possibly be written.
+func (l *Logger) lowestThreshold(lvl Level) int32 {
+	lowest := lvl.threshold()
+	for _, p := range l.packageLevels {
+		if t := p.level.threshold(); t < lowest {
+			lowest = t
+		}
+	}
+	return lowest
+}
+
+// inPackage reports whether file is in the package at prefix, or beneath it.
+func inPackage(file, prefix string) bool {
+	for i := strings.Index(file, prefix); i >= 0; {
+		end := i + len(prefix)
+		if (i == 0 || file[i-1] == '/') && end < len(file) && file[end] == '/' {
+			return true
+		}
+		next := strings.Index(file[i+1:], prefix)
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPackageLevelSentry(t *testing.T) {
+	log, transport := newSentryTestLogger(t)
+	var buf bytes.Buffer
+	log.SetOutput(&buf).SetLevel(ErrorLvl)
+	log.formatter = upperFormatter{}
+	_, file, _, _ := runtime.Caller(0)
+	log.SetPackageLevel(strings.TrimPrefix(filepath.Dir(file), "/"), WarnLvl)
+	log.SetPackageLevel("github.com/other/storage", DebugLvl)
+	log.Warn("written")
+	log.Error("sent")
+	if err := log.FlushSentry(time.Second); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if buf.String() != "WARN: written\nERROR: sent\n" {
+		t.Errorf("Expected the package level to include WARN, got '%s' instead\n", buf.String())
+	}
+	transport.Lock()
+	defer transport.Unlock()
+	if len(transport.packets) != 1 || strings.TrimSpace(transport.packets[0].Message) != "sent" {
+		t.Errorf("Expected only the ERROR to be sent to Sentry, got %d events instead\n", len(transport.packets))
+	}
+}
+
+func TestInPackage(t *testing.T) {
+	cases := []struct {
+		file, prefix string
+		expected     bool
+	}{
+		{"/go/src/github.com/org/app/storage/db.go", "github.com/org/app/storage", true},
+		{"/go/src/github.com/org/app/storage/sql/db.go", "github.com/org/app/storage", true},
+		{"/go/src/github.com/org/app/storagex/db.go", "github.com/org/app/storage", false},
+		{"/go/src/github.com/org/app/main.go", "github.com/org/app/storage", false},
+		{"/go/src/github.com/notorg/app/storage/db.go", "org/app/storage", false},
+		{"/go/src/x/org/app/storage/org/app/storage/db.go", "org/app/storage", true},
+		{"github.com/org/app/storage/db.go", "github.com/org/app/storage", true},
+	}
+	for _, c := range cases {
+		if got := inPackage(c.file, c.prefix); got != c.expected {
+			t.Errorf("Expected inPackage(%q, %q) to be %t, got %t instead\n", c.file, c.prefix, c.expected, got)
+		}
+	}
+}
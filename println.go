@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The ln methods behave like the standard library's log.Println: their arguments are always joined as by
+// fmt.Sprintln, with a space between every pair of them, whatever the Logger's separator, and the entry
+// ends with exactly one newline, even if the last argument ends with newlines of its own. They are meant
+// for code moving over from the log package.
+
+// Infoln writes a log entry with the Level of InfoLvl, joining each argument passed as fmt.Sprintln does.
+func (l *Logger) Infoln(msg ...interface{}) {
+	if !l.enabled(InfoLvl) {
+		return
+	}
+	l.logln(InfoLvl, msg...)
+}
+
+// Warnln writes a log entry with the Level of WarnLvl, joining each argument passed as fmt.Sprintln does.
+//
+// Any message logged with Warnln will automatically be sent to Sentry, if Sentry
+// has been configured. The event is sent in the background; see FlushSentry.
+func (l *Logger) Warnln(msg ...interface{}) {
+	if !l.enabled(WarnLvl) {
+		return
+	}
+	l.logln(WarnLvl, msg...)
+	l.toSentry("%s", []interface{}{fmt.Sprintln(msg...)}, WarnLvl, nil)
+}
+
+// Errorln writes a log entry with the Level of ErrorLvl, joining each argument passed as fmt.Sprintln
+// does.
+//
+// Any message logged with Errorln will automatically be sent to Sentry, if Sentry
+// has been configured. The event is sent in the background; see FlushSentry.
+func (l *Logger) Errorln(msg ...interface{}) {
+	if !l.enabled(ErrorLvl) {
+		return
+	}
+	l.logln(ErrorLvl, msg...)
+	l.toSentry("%s", []interface{}{fmt.Sprintln(msg...)}, ErrorLvl, nil)
+}
+
+func (l *Logger) logln(lvl Level, msg ...interface{}) {
+	err := l.output(l.calldepth+3, strings.TrimRight(fmt.Sprintln(msg...), "\n"), lvl)
+	if err != nil {
+		l.handleError(err)
+	}
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintln(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetSeparator("")
+
+	methods := map[Level]func(...interface{}){
+		DebugLvl: log.Debugln,
+		InfoLvl:  log.Infoln,
+		WarnLvl:  log.Warnln,
+		ErrorLvl: log.Errorln,
+	}
+	for lvl, method := range methods {
+		if lvl == DebugLvl && !debugEnabled {
+			continue
+		}
+		buf.Reset()
+		method("a", "b", 1, "done\n\n")
+		expected := "[" + string(lvl) + "] "
+		if !strings.Contains(buf.String(), expected) || !strings.HasSuffix(buf.String(), ": a b 1 done\n") {
+			t.Errorf("Expected a %s entry ending with ': a b 1 done', got '%s' instead\n", lvl, buf.String())
+		}
+		if !strings.Contains(buf.String(), "println_test.go:") {
+			t.Errorf("Expected the caller to be the test, got '%s' instead\n", buf.String())
+		}
+	}
+
+	buf.Reset()
+	log.SetLevel(WarnLvl).Infoln("suppressed")
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output below the Logger's Level, got '%s' instead\n", buf.String())
+	}
+}
+
+func TestPrintlnSentry(t *testing.T) {
+	log, transport := newSentryTestLogger(t)
+	log.Errorln("progress:", "100%")
+	log.FlushSentry(time.Second)
+	if len(transport.packets) != 1 || transport.packets[0].Message != "progress: 100%\n" {
+		t.Errorf("Expected 'progress: 100%%' to be sent as is, got %+v instead\n", transport.packets)
+	}
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// Raw writes line to the Logger's output as is, for lines that are already formatted, like the output of
+// a subprocess. The caller owns the formatting entirely: no header, fields, or prefix are added, and the
+// Logger's Formatter isn't used. Only the Logger's line terminator, a newline by default, is added, and
+// only if line doesn't already end with it. Of the Logger's processing, only its redaction patterns are
+// applied; see SetRedactPatterns.
+//
+// The line is still written only if the Logger's Level includes level, and lines at WarnLvl and ErrorLvl
+// are sent to Sentry, if Sentry has been configured, like those of the other log methods. Since Raw
+// doesn't build an Entry, hooks aren't fired for it.
+func (l *Logger) Raw(level Level, line string) {
+	if !l.enabled(level) {
+		return
+	}
+	line = l.redactString(line)
+	if atomic.LoadInt32(&l.discard) == 0 && !l.closer.isClosed() {
+		buf := getBuffer()
+		*buf = append(*buf, line...)
+		if !strings.HasSuffix(line, l.lineTerminator) {
+			*buf = append(*buf, l.lineTerminator...)
+		}
+		err := l.emit(level, *buf)
+		putBuffer(buf)
+		if err != nil {
+			l.handleError(err)
+		}
+	}
+	if level == WarnLvl || level == ErrorLvl {
+		l.toSentry("%s", []interface{}{line}, level, nil)
+	}
+}
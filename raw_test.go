@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestRaw(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Raw(InfoLvl, "12:00:01 child started")
+	log.Raw(WarnLvl, "12:00:02 child warned\n")
+	log.Raw(DebugLvl, "12:00:03 child debugged")
+	expected := "12:00:01 child started\n12:00:02 child warned\n"
+	if buf.String() != expected {
+		t.Errorf("Expected '%s', got '%s' instead\n", expected, buf.String())
+	}
+
+	buf.Reset()
+	log.SetRedactPatterns(regexp.MustCompile(`token=\S+`)).SetLineTerminator("\r\n")
+	log.Raw(InfoLvl, "child token=secret")
+	expected = "child " + Redacted + "\r\n"
+	if buf.String() != expected {
+		t.Errorf("Expected '%s', got '%s' instead\n", expected, buf.String())
+	}
+}
+
+func TestRawSentry(t *testing.T) {
+	log, transport := newSentryTestLogger(t)
+	log.Raw(WarnLvl, "GET /?q=%20 200")
+	log.FlushSentry(time.Second)
+	if len(transport.packets) != 1 || transport.packets[0].Message != "GET /?q=%20 200" {
+		t.Errorf("Expected the line to be sent as is, got %+v instead\n", transport.packets)
+	}
+}
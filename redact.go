@@ -0,0 +1,184 @@
+package logging
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/DramaFever/raven-go"
+)
+
+// Redacted replaces anything removed by a redaction pattern.
+const Redacted = "[REDACTED]"
+
+var (
+	// CreditCardPattern matches runs of 13 to 19 digits, optionally separated by spaces or dashes, as
+	// used by payment card numbers.
+	CreditCardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	// BearerTokenPattern matches bearer tokens, as sent in HTTP Authorization headers.
+	BearerTokenPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+// DefaultRedactPatterns returns the redaction patterns that ship with the package, for use with
+// SetRedactPatterns.
+func DefaultRedactPatterns() []*regexp.Regexp {
+	return []*regexp.Regexp{CreditCardPattern, BearerTokenPattern}
+}
+
+// SetRedactor sets a function that is called with the key and value of each field before it is written.
+// The value the function returns is written in its place, so it can mask secrets, like auth headers or
+// emails, before they reach the Logger's output. It is also applied to the extra context of Sentry
+// events, and to the headers, cookies, and query parameters of the requests they carry, keyed by name.
+// Passing nil removes the redactor.
+func (l *Logger) SetRedactor(redactor func(key, value string) string) *Logger {
+	l.redactor = redactor
+	return l
+}
+
+// SetRedactPatterns sets regular expressions whose matches are replaced with Redacted in every message
+// and field value before they are written, and in the messages, error messages, extra context, and
+// request headers, cookies, and query strings of Sentry events. See DefaultRedactPatterns for a set of
+// common patterns.
+// Calling SetRedactPatterns with no patterns removes any previously set.
+func (l *Logger) SetRedactPatterns(patterns ...*regexp.Regexp) *Logger {
+	l.redactPatterns = patterns
+	return l
+}
+
+// redactString replaces every match of the Logger's redaction patterns in s.
+func (l *Logger) redactString(s string) string {
+	for _, re := range l.redactPatterns {
+		s = re.ReplaceAllString(s, Redacted)
+	}
+	return s
+}
+
+// redactValue applies the Logger's redaction patterns, then its redactor, to value, the value of key.
+func (l *Logger) redactValue(key, value string) string {
+	redacted := l.redactString(value)
+	if l.redactor != nil {
+		redacted = l.redactor(key, redacted)
+	}
+	return redacted
+}
+
+// redacting reports whether the Logger has any redaction to apply.
+func (l *Logger) redacting() bool {
+	return l.redactor != nil || len(l.redactPatterns) > 0
+}
+
+// redact applies the Logger's redaction to the message and fields of entry. Fields are only copied if
+// there is redaction to apply, and only values the redaction changes are replaced.
+func (l *Logger) redact(entry *Entry) {
+	if !l.redacting() {
+		return
+	}
+	entry.Message = l.redactString(entry.Message)
+	if len(entry.Fields) > 0 {
+		entry.Fields = l.redactFields(entry.Fields)
+	}
+	if len(entry.typed) > 0 {
+		entry.typed = l.redactTyped(entry.typed)
+	}
+}
+
+// redactParams returns a copy of the params of a message bound for Sentry, with the Logger's redaction
+// patterns applied to each of them, formatted as by fmt.Sprint. Params the patterns don't change are
+// left as they are.
+func (l *Logger) redactParams(params []interface{}) []interface{} {
+	if len(l.redactPatterns) == 0 || len(params) == 0 {
+		return params
+	}
+	redacted := make([]interface{}, len(params))
+	for i, p := range params {
+		redacted[i] = p
+		value := fmt.Sprint(p)
+		if r := l.redactString(value); r != value {
+			redacted[i] = r
+		}
+	}
+	return redacted
+}
+
+// redactFields returns a copy of fields with the Logger's redaction applied to its values, and to the
+// values of its groups.
+func (l *Logger) redactFields(fields Fields) Fields {
+	redactedFields := make(Fields, len(fields))
+	for k, v := range fields {
+		if group, ok := v.(Fields); ok {
+			redactedFields[k] = l.redactFields(group)
+			continue
+		}
+		redactedFields[k] = v
+		value := fmt.Sprint(v)
+		redacted := l.redactValue(k, value)
+		if redacted != value {
+			redactedFields[k] = redacted
+		}
+	}
+	return redactedFields
+}
+
+// redactTyped returns a copy of the typed fields of an entry with the Logger's redaction applied to their
+// values. Fields whose values are redacted become strings.
+func (l *Logger) redactTyped(fields []Field) []Field {
+	redacted := make([]Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = f
+		if f.kind == skipField {
+			continue
+		}
+		value := f.str
+		if f.kind != stringField {
+			value = fmt.Sprint(f.Value())
+		}
+		r := l.redactValue(f.Key, value)
+		if r != value {
+			redacted[i] = String(f.Key, r)
+		}
+	}
+	return redacted
+}
+
+// redactHTTP returns a copy of the request h describes, with the Logger's redaction applied to its
+// headers, cookies, and query parameters, each keyed by its name. raven copies every header of a request,
+// including Authorization and Cookie, so these would otherwise leak secrets the output hides. h itself is
+// returned if there is no redaction to apply.
+func (l *Logger) redactHTTP(h *raven.Http) *raven.Http {
+	if !l.redacting() {
+		return h
+	}
+	redacted := *h
+	if len(h.Headers) > 0 {
+		redacted.Headers = make(map[string]string, len(h.Headers))
+		for k, v := range h.Headers {
+			redacted.Headers[k] = l.redactValue(k, v)
+		}
+	}
+	if h.Cookies != "" {
+		cookies := strings.Split(h.Cookies, ";")
+		for i, cookie := range cookies {
+			if eq := strings.IndexByte(cookie, '='); eq >= 0 {
+				cookies[i] = cookie[:eq+1] + l.redactValue(strings.TrimSpace(cookie[:eq]), cookie[eq+1:])
+			} else {
+				cookies[i] = l.redactString(cookie)
+			}
+		}
+		redacted.Cookies = strings.Join(cookies, ";")
+	}
+	if h.Query != "" {
+		query, err := url.ParseQuery(h.Query)
+		if err != nil {
+			redacted.Query = l.redactString(h.Query)
+		} else {
+			for k, values := range query {
+				for i, v := range values {
+					values[i] = l.redactValue(k, v)
+				}
+			}
+			redacted.Query = query.Encode()
+		}
+	}
+	return &redacted
+}
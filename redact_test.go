@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRedactPatterns(t *testing.T) {
+	redactTests := map[string]string{
+		"charged 4111 1111 1111 1111 today":            "charged [REDACTED] today",
+		"charged 4111-1111-1111-1111":                  "charged [REDACTED]",
+		"order 12345 shipped":                          "order 12345 shipped",
+		"Authorization: Bearer abc.DEF-123_xyz== sent": "Authorization: [REDACTED] sent",
+	}
+	log, err := New(DebugLvl, &bytes.Buffer{}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.SetRedactPatterns(DefaultRedactPatterns()...)
+	for in, out := range redactTests {
+		result := log.redactString(in)
+		if result != out {
+			t.Errorf("Expected '%s' to be redacted to '%s', got '%s' instead\n", in, out, result)
+		}
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.SetRedactPatterns(BearerTokenPattern).SetRedactor(func(key, value string) string {
+		if key == "email" {
+			return "***"
+		}
+		return value
+	})
+	child := log.Child(Fields{"email": "gopher@example.com", "auth": "Bearer secret", "status": 200})
+	child.Info("sent Bearer secret")
+	expected := ": sent [REDACTED] auth=[REDACTED] email=*** status=200\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with '%s', got '%s' instead\n", expected, buf.String())
+	}
+	if child.GetFields()["email"] != "gopher@example.com" {
+		t.Errorf("Expected the Logger's fields to be unchanged, got %+v\n", child.GetFields())
+	}
+}
@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// ReopeningFile is an io.WriteCloser for a log file that reopens its path when the file it has open is
+// deleted or replaced, like when an operator removes it or a log rotator moves it aside without
+// signalling the process. Without it, a process keeps writing to the unlinked file, and the output is
+// lost. It is safe for concurrent use.
+//
+// At most once per polling interval, a Write checks whether the path still refers to the open file, and
+// reopens it if not. A Write that fails also reopens the path, and the rest of it is retried once. Once
+// the ReopeningFile is closed, Write and Reopen return os.ErrClosed.
+type ReopeningFile struct {
+	path     string
+	interval time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	f         *os.File
+	checkedAt time.Time
+	closed    bool
+}
+
+// OpenReopeningFile opens the file at path for appending, creating it if it doesn't exist, and returns a
+// ReopeningFile that checks the path at most once per interval.
+func OpenReopeningFile(path string, interval time.Duration) (*ReopeningFile, error) {
+	r := &ReopeningFile{path: path, interval: interval, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LogToReopeningFile creates a new Logger that writes to the file specified by path, reopening it when
+// it is deleted or replaced. The path is checked at most once per interval. See ReopeningFile for
+// details.
+//
+// If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
+// are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
+// the version of your software running, etc.
+func LogToReopeningFile(level Level, path string, interval time.Duration, sentry string, sentryTags map[string]string) (*Logger, error) {
+	f, err := OpenReopeningFile(path, interval)
+	if err != nil {
+		return nil, err
+	}
+	return New(level, f, sentry, sentryTags)
+}
+
+// Write writes p to the file, reopening the path first if the file has been deleted or replaced.
+func (r *ReopeningFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if now := r.now(); now.Sub(r.checkedAt) >= r.interval {
+		r.checkedAt = now
+		if r.replaced() {
+			if err := r.reopen(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	n, err := r.f.Write(p)
+	if err != nil {
+		if reopenErr := r.reopen(); reopenErr != nil {
+			return n, err
+		}
+		m, err := r.f.Write(p[n:])
+		return n + m, err
+	}
+	return n, nil
+}
+
+// Reopen closes the file and opens the path again. It can be called when the file is known to have
+// been rotated, like from a SIGHUP handler.
+func (r *ReopeningFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return os.ErrClosed
+	}
+	return r.reopen()
+}
+
+// Close closes the file. Closing it again returns os.ErrClosed.
+func (r *ReopeningFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return os.ErrClosed
+	}
+	r.closed = true
+	return r.f.Close()
+}
+
+// replaced reports whether the path no longer refers to the open file. The caller must hold r.mu.
+func (r *ReopeningFile) replaced() bool {
+	current, err := os.Stat(r.path)
+	if err != nil {
+		return true
+	}
+	open, err := r.f.Stat()
+	if err != nil {
+		return true
+	}
+	return !os.SameFile(current, open)
+}
+
+// reopen closes the file and opens the path again. The caller must hold r.mu.
+func (r *ReopeningFile) reopen() error {
+	old := r.f
+	if err := r.open(); err != nil {
+		return err
+	}
+	old.Close()
+	return nil
+}
+
+// open opens the path for appending. The caller must hold r.mu, or be the only user of r.
+func (r *ReopeningFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	r.f = f
+	return nil
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReopeningFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-logging")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.log")
+
+	now := time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC)
+	f, err := OpenReopeningFile(path, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	f.now = func() time.Time { return now }
+	log, err := New(InfoLvl, f, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer log.Close()
+
+	log.Info("first")
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log.Info("lost")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the path not to be checked before the interval passed, got %+v\n", err)
+	}
+
+	now = now.Add(time.Minute)
+	log.Info("second")
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the file to be recreated, got %+v\n", err)
+	}
+	if strings.Contains(string(contents), "first") || !strings.HasSuffix(string(contents), "second\n") {
+		t.Errorf("Expected only the line written after reopening, got '%s' instead\n", contents)
+	}
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log.Info("third")
+	contents, err = ioutil.ReadFile(path)
+	if err != nil || !strings.HasSuffix(string(contents), "third\n") || strings.Contains(string(contents), "second") {
+		t.Errorf("Expected Reopen to start a new file, got '%s' (%+v) instead\n", contents, err)
+	}
+}
+
+func TestReopeningFileClosed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-logging")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.log")
+
+	f, err := OpenReopeningFile(path, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if _, err := f.Write([]byte("late\n")); err != os.ErrClosed {
+		t.Errorf("Expected os.ErrClosed writing after Close, got %+v instead\n", err)
+	}
+	if err := f.Reopen(); err != os.ErrClosed {
+		t.Errorf("Expected os.ErrClosed reopening after Close, got %+v instead\n", err)
+	}
+	if f.f.Fd() != ^uintptr(0) {
+		t.Error("Expected the file to stay closed")
+	}
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// RequestIDHeader is the header RequestIDHandler reads a request's ID from, and writes it to.
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDField is the field a request's ID is logged in by the Logger RequestIDHandler stores.
+	RequestIDField = "request_id"
+
+	// maxRequestIDLength is the longest incoming request ID that is trusted.
+	maxRequestIDLength = 128
+)
+
+// RequestIDHandler returns an http.Handler that gives each request an ID and a child of l that logs it,
+// before calling next. The ID is read from the request's X-Request-ID header, so it follows the request
+// across services, or generated as a random UUID if the header is missing or malformed. It is written to
+// the response's X-Request-ID header, and the child Logger, with the ID in its request_id field, is stored
+// in the request's Context, where handlers can retrieve it with LogFromContext.
+func RequestIDHandler(l *Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := SaveToContext(l.Child(Fields{RequestIDField: id}), r.Context())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID reports whether id can be used as a request ID: it must be short, and only hold
+// printable ASCII, so a client can't use it to inject headers or log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random version 4 UUID.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("logging: can't read random bytes for a request ID: " + err.Error())
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestIDHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	handler := RequestIDHandler(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		LogFromContext(r.Context()).Info("handled")
+	}))
+
+	requestTests := []struct {
+		header   string
+		expected string
+	}{
+		{"abc-123", "abc-123"},
+		{"", ""},
+		{"bad\x01id", ""},
+		{strings.Repeat("x", maxRequestIDLength+1), ""},
+	}
+	for _, test := range requestTests {
+		buf.Reset()
+		req := httptest.NewRequest("GET", "/", nil)
+		if test.header != "" {
+			req.Header.Set(RequestIDHeader, test.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		id := rec.Header().Get(RequestIDHeader)
+		if test.expected != "" && id != test.expected {
+			t.Errorf("Expected the request ID %q to be echoed, got %q instead\n", test.expected, id)
+		}
+		if test.expected == "" && !uuidPattern.MatchString(id) {
+			t.Errorf("Expected a generated UUID for %q, got %q instead\n", test.header, id)
+		}
+		if !strings.HasSuffix(buf.String(), "handled request_id="+id+"\n") {
+			t.Errorf("Expected the request ID to be logged, got %q instead\n", buf.String())
+		}
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		id := newRequestID()
+		if !uuidPattern.MatchString(id) || seen[id] {
+			t.Fatalf("Expected a new version 4 UUID, got %q instead\n", id)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,156 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Facility is a syslog facility, as defined by RFC 5424. It is combined with the severity of each
+// entry's Level to form the syslog priority.
+type Facility int
+
+// Syslog facilities.
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	_
+	_
+	_
+	_
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// DefaultStructuredDataID is the SD-ID used for Fields when an RFC5424Formatter has none set. 32473 is
+// the private enterprise number reserved for documentation by RFC 5612.
+const DefaultStructuredDataID = "fields@32473"
+
+// RFC5424Formatter renders an Entry as an RFC 5424 syslog message, like
+//
+//	<12>1 2015-07-02T13:28:42.000000Z web-1 myapp 1234 - [fields@32473 key="value"] /my/test/file.go:145: message
+//
+// The Logger's Fields are written as a single structured data element. Empty header values are written
+// as "-", as RFC 5424 requires.
+type RFC5424Formatter struct {
+	// Facility is combined with the severity of the entry's Level to form the priority.
+	Facility Facility
+	// Hostname is the name of the machine that sent the message.
+	Hostname string
+	// AppName identifies the application that sent the message.
+	AppName string
+	// ProcID identifies the process that sent the message, usually its PID.
+	ProcID string
+	// MsgID identifies the type of message. It is usually left empty.
+	MsgID string
+	// StructuredDataID is the SD-ID of the element that holds the Fields. It defaults to
+	// DefaultStructuredDataID.
+	StructuredDataID string
+}
+
+// NewRFC5424Formatter returns an RFC5424Formatter for the given facility and app name, whose Hostname
+// and ProcID are those of the current process.
+func NewRFC5424Formatter(facility Facility, appName string) RFC5424Formatter {
+	hostname, _ := os.Hostname()
+	return RFC5424Formatter{
+		Facility: facility,
+		Hostname: hostname,
+		AppName:  appName,
+		ProcID:   strconv.Itoa(os.Getpid()),
+	}
+}
+
+// Format appends e to buf as an RFC 5424 syslog message, without any transport framing.
+func (f RFC5424Formatter) Format(buf *[]byte, e *Entry) {
+	*buf = append(*buf, '<')
+	itoa(buf, int(f.Facility)*8+e.Level.syslogSeverity(), -1)
+	*buf = append(*buf, ">1 "...)
+	*buf = e.Time.AppendFormat(*buf, "2006-01-02T15:04:05.000000Z07:00")
+	appendSyslogHeaderField(buf, f.Hostname, 255)
+	appendSyslogHeaderField(buf, f.AppName, 48)
+	appendSyslogHeaderField(buf, f.ProcID, 128)
+	appendSyslogHeaderField(buf, f.MsgID, 32)
+	*buf = append(*buf, ' ')
+	if len(e.Fields) == 0 {
+		*buf = append(*buf, '-')
+	} else {
+		id := f.StructuredDataID
+		if id == "" {
+			id = DefaultStructuredDataID
+		}
+		*buf = append(*buf, '[')
+		*buf = append(*buf, id...)
+		fields := e.Fields.flatten()
+		for _, k := range fields.sortedKeys() {
+			*buf = append(*buf, ' ')
+			*buf = append(*buf, syslogName(k, 32)...)
+			*buf = append(*buf, '=', '"')
+			appendSyslogParamValue(buf, fmt.Sprint(fields[k]))
+			*buf = append(*buf, '"')
+		}
+		*buf = append(*buf, ']')
+	}
+	*buf = append(*buf, ' ')
+	if e.File != "" {
+		*buf = append(*buf, e.File...)
+		*buf = append(*buf, ':')
+		itoa(buf, e.Line, -1)
+		*buf = append(*buf, ": "...)
+	}
+	*buf = append(*buf, e.Message...)
+}
+
+// appendSyslogHeaderField appends a space and then s to buf, as a header field of at most max
+// characters, or "-" if s is empty.
+func appendSyslogHeaderField(buf *[]byte, s string, max int) {
+	*buf = append(*buf, ' ')
+	if s == "" {
+		*buf = append(*buf, '-')
+		return
+	}
+	*buf = append(*buf, syslogName(s, max)...)
+}
+
+// syslogName returns s truncated to max characters, with any characters that RFC 5424 doesn't allow in
+// names replaced with underscores. Names may only contain printable ASCII, and never '=', ']', or '"'.
+func syslogName(s string, max int) string {
+	if len(s) > max {
+		s = s[:max]
+	}
+	name := []byte(s)
+	for i, c := range name {
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+// appendSyslogParamValue appends s to buf as a structured data parameter value, escaping the characters
+// RFC 5424 requires.
+func appendSyslogParamValue(buf *[]byte, s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\', ']':
+			*buf = append(*buf, '\\', c)
+		default:
+			*buf = append(*buf, c)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRFC5424Formatter(t *testing.T) {
+	f := RFC5424Formatter{Facility: FacilityLocal0, Hostname: "web-1", AppName: "my app", ProcID: "1234"}
+	e := &Entry{
+		Time:    time.Date(2015, time.July, 2, 13, 28, 42, 123456789, time.UTC),
+		Level:   WarnLvl,
+		File:    "/my/test/file.go",
+		Line:    145,
+		Message: "message",
+		Fields:  Fields{"user": "bob", "path": `a"b]c\d`},
+	}
+	var buf []byte
+	f.Format(&buf, e)
+	expected := `<132>1 2015-07-02T13:28:42.123456Z web-1 my_app 1234 - [fields@32473 path="a\"b\]c\\d" user="bob"] /my/test/file.go:145: message`
+	if string(buf) != expected {
+		t.Errorf("Expected output to be '%s', got '%s' instead\n", expected, buf)
+	}
+}
+
+func TestRFC5424FormatterEmpty(t *testing.T) {
+	f := RFC5424Formatter{StructuredDataID: "x@1"}
+	e := &Entry{Time: time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC), Level: DebugLvl, File: "f.go", Line: 1, Message: "m"}
+	var buf []byte
+	f.Format(&buf, e)
+	expected := "<7>1 2015-07-02T13:28:42.000000Z - - - - - f.go:1: m"
+	if string(buf) != expected {
+		t.Errorf("Expected output to be '%s', got '%s' instead\n", expected, buf)
+	}
+}
+
+func TestWithRFC5424(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithRFC5424(FacilityDaemon, "myapp"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	f, ok := log.formatter.(RFC5424Formatter)
+	if !ok {
+		t.Fatalf("Expected an RFC5424Formatter, got %T instead\n", log.formatter)
+	}
+	if f.Facility != FacilityDaemon || f.AppName != "myapp" || f.ProcID == "" {
+		t.Errorf("Expected the formatter to be configured, got %+v instead\n", f)
+	}
+	log.Error("boom")
+	if !bytes.HasPrefix(buf.Bytes(), []byte("<27>1 ")) {
+		t.Errorf("Expected output to start with '<27>1 ', got '%s' instead\n", buf.String())
+	}
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sampleReportInterval is the least time between the entries that report how many messages at a Level
+// were sampled out.
+const sampleReportInterval = time.Minute
+
+// sampler counts the messages at each Level and decides which are written. Levels are indexed by their
+// severity.
+type sampler struct {
+	rates   [4]uint64
+	counts  [4]uint64
+	dropped [4]uint64
+
+	mu         sync.Mutex
+	reportedAt [4]time.Time
+}
+
+// SetSampleRate makes the Logger write only every nth message logged at level, and drop the rest. The
+// first message is always written. This keeps a representative sample of a hot code path, like a
+// per-request DEBUG statement, deterministically rather than by time. At most once a minute, when a
+// sampled message is written, an extra entry at the same Level reports how many messages were dropped
+// since the last report. A rate of 0 or 1 writes every message.
+//
+// Child Loggers created after SetSampleRate share its counters, so a code path is sampled as a whole no
+// matter which child it logs through.
+func (l *Logger) SetSampleRate(level Level, n int) *Logger {
+	s := &sampler{}
+	if l.sampler != nil {
+		s.rates = l.sampler.rates
+	}
+	if n < 0 {
+		n = 0
+	}
+	s.rates[level.severity()] = uint64(n)
+	l.sampler = s
+	return l
+}
+
+// sample reports whether a message at lvl should be written. If it should, and messages have been
+// dropped since the last report, sample also returns the number dropped, and resets it.
+func (s *sampler) sample(lvl Level, now time.Time) (ok bool, dropped uint64) {
+	if s == nil {
+		return true, 0
+	}
+	i := lvl.severity()
+	rate := s.rates[i]
+	if rate <= 1 {
+		return true, 0
+	}
+	if (atomic.AddUint64(&s.counts[i], 1)-1)%rate != 0 {
+		atomic.AddUint64(&s.dropped[i], 1)
+		return false, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.reportedAt[i]) < sampleReportInterval {
+		return true, 0
+	}
+	dropped = atomic.SwapUint64(&s.dropped[i], 0)
+	if dropped > 0 {
+		s.reportedAt[i] = now
+	}
+	return true, dropped
+}
@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetSampleRateChildren(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithFormatter(upperFormatter{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	log.SetSampleRate(InfoLvl, 2)
+	child := log.Child(Fields{"k": "v"})
+	log.Info("one")
+	child.Info("two")
+	child.Info("three")
+	if buf.String() != "INFO: one\nINFO: sampled out 1 INFO messages\nINFO: three\n" {
+		t.Errorf("Expected children to share the sample counter, got '%s' instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log.SetSampleRate(InfoLvl, 1)
+	log.Info("four")
+	log.Info("five")
+	if buf.String() != "INFO: four\nINFO: five\n" {
+		t.Errorf("Expected a rate of 1 to write everything, got '%s' instead\n", buf.String())
+	}
+}
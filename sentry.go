@@ -0,0 +1,262 @@
+package logging
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/DramaFever/raven-go"
+)
+
+// ErrSentryFlushTimeout is returned by FlushSentry when events are still being sent to Sentry after the
+// timeout has passed.
+var ErrSentryFlushTimeout = errors.New("timed out waiting for events to be sent to Sentry")
+
+// Sentry returns the Sentry client the Logger sends errors to, for capturing events that aren't tied to a
+// log message. It returns nil if Sentry has not been configured, which callers must check for. The client
+// is shared with the Logger, so closing it affects the Logger too. The Logger's Sentry tags are sent with
+// its own events, rather than set on the client, so events captured with the client directly don't carry
+// them.
+func (l *Logger) Sentry() *raven.Client {
+	return l.sentry
+}
+
+// sentryTagSet holds the tags sent with every Sentry event from a Logger, and from the Loggers derived
+// from it, which share it along with the Sentry client. The tags are passed to raven with each event,
+// rather than set on the client, which raven reads without locking.
+type sentryTagSet struct {
+	mu   sync.RWMutex
+	tags map[string]string
+}
+
+// newSentryTagSet returns a sentryTagSet holding a copy of tags.
+func newSentryTagSet(tags map[string]string) *sentryTagSet {
+	set := &sentryTagSet{}
+	set.replace(tags)
+	return set
+}
+
+// merge merges tags into the set, replacing any existing values for the same keys.
+func (s *sentryTagSet) merge(tags map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	merged := make(map[string]string, len(s.tags)+len(tags))
+	for k, v := range s.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	s.tags = merged
+}
+
+// replace replaces the tags in the set with a copy of tags.
+func (s *sentryTagSet) replace(tags map[string]string) {
+	replaced := make(map[string]string, len(tags))
+	for k, v := range tags {
+		replaced[k] = v
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tags = replaced
+}
+
+// with returns the tags in the set merged with tags, the tags of a single Logger, which win.
+func (s *sentryTagSet) with(tags map[string]string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	merged := make(map[string]string, len(s.tags)+len(tags))
+	for k, v := range s.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return merged
+}
+
+// AddSentryTags merges tags into the tags sent with the Logger's Sentry events, replacing any existing
+// values for the same keys. The tags apply to every Logger sharing the Sentry client, and can be changed
+// while they are logging. It does nothing if Sentry has not been configured.
+func (l *Logger) AddSentryTags(tags map[string]string) *Logger {
+	if l.sentry == nil {
+		return l
+	}
+	l.sentryTags.merge(tags)
+	return l
+}
+
+// SetSentryTags replaces the tags sent with the Logger's Sentry events. The tags apply to every Logger
+// sharing the Sentry client, and can be changed while they are logging. It does nothing if Sentry has not
+// been configured.
+func (l *Logger) SetSentryTags(tags map[string]string) *Logger {
+	if l.sentry == nil {
+		return l
+	}
+	l.sentryTags.replace(tags)
+	return l
+}
+
+// SetSentryEnvironment sets the environment, like "production" or "staging", reported with Sentry events,
+// so they can be filtered by environment. Unlike a tag, Sentry uses the environment to scope releases and
+// alerts. It applies to every Logger sharing the Sentry client, and is kept if the client is replaced by
+// SetSentry. An empty environment is ignored.
+func (l *Logger) SetSentryEnvironment(environment string) *Logger {
+	if environment == "" {
+		return l
+	}
+	l.sentryEnv = environment
+	l.configureSentry()
+	return l
+}
+
+// SetSentryRelease sets the release, usually a version or git SHA1, reported with Sentry events, which
+// drives Sentry's release health and regression detection. It applies to every Logger sharing the Sentry
+// client, and is kept if the client is replaced by SetSentry. An empty release is ignored.
+func (l *Logger) SetSentryRelease(release string) *Logger {
+	if release == "" {
+		return l
+	}
+	l.sentryRelease = release
+	l.configureSentry()
+	return l
+}
+
+// sentryRand returns a random number in [0, 1) to decide whether an event is sampled.
+var sentryRand = rand.Float64
+
+// SetSentrySampleRate sets the fraction of eligible events, from 0 to 1, that the Logger sends to Sentry,
+// to stay within quota during an error storm. Each event is sampled at random; the rest are still written
+// to the Logger's output, just not sent to Sentry. It defaults to 1, which sends every event. Rates
+// outside 0 to 1 are clamped.
+func (l *Logger) SetSentrySampleRate(rate float64) *Logger {
+	if rate < 0 {
+		rate = 0
+	}
+	l.sentrySampled = rate < 1
+	l.sentrySampleRate = rate
+	return l
+}
+
+// configureSentry applies the environment and release set on the Logger to its Sentry client, if it has
+// one.
+func (l *Logger) configureSentry() {
+	if l.sentry == nil {
+		return
+	}
+	if l.sentryEnv != "" {
+		l.sentry.SetEnvironment(l.sentryEnv)
+	}
+	if l.sentryRelease != "" {
+		l.sentry.SetRelease(l.sentryRelease)
+	}
+}
+
+// WithSentryExtra copies the Logger, adds extra to the Sentry "extra" context of the copy, and returns the
+// modified copy. It is meant to attach structured context, like a request body or user ID, to the Sentry
+// events for specific log messages. The data is unused if Sentry is not configured on the Logger.
+func (l *Logger) WithSentryExtra(extra map[string]interface{}) *Logger {
+	newLogger := l.makeCopy()
+	if newLogger.extra == nil {
+		newLogger.extra = make(map[string]interface{}, len(extra))
+	}
+	for k, v := range extra {
+		newLogger.extra[k] = v
+	}
+	return newLogger
+}
+
+// WithSentryUser copies the Logger, sets the user the Sentry events sent by the copy are about, and
+// returns the modified copy, so an error in a request handler shows which user hit it. The ipAddr is
+// optional: pass an empty string to leave the user's IP address out of the events. A user set earlier on
+// the Logger is replaced. The user is unused if Sentry is not configured on the Logger.
+func (l *Logger) WithSentryUser(id, email, ipAddr string) *Logger {
+	newLogger := l.makeCopy()
+	meta := newLogger.meta[:0]
+	for _, i := range newLogger.meta {
+		if _, ok := i.(*raven.User); !ok {
+			meta = append(meta, i)
+		}
+	}
+	newLogger.meta = append(meta, &raven.User{ID: id, Email: email, IP: ipAddr})
+	return newLogger
+}
+
+// WithFingerprint copies the Logger, sets the fingerprint Sentry uses to group the events sent by the copy
+// into issues, and returns the modified copy. Events with the same fingerprint are grouped together,
+// regardless of their messages; the special value "{{ default }}" stands in for Sentry's own grouping.
+// The fingerprint is unused if Sentry is not configured on the Logger.
+func (l *Logger) WithFingerprint(fingerprint ...string) *Logger {
+	newLogger := l.makeCopy()
+	newLogger.fingerprint = append([]string(nil), fingerprint...)
+	return newLogger
+}
+
+// FlushSentry waits for the events the Logger (and any Logger copied from it) has sent to Sentry to be
+// delivered, for at most timeout. Events are sent in the background so that logging an error doesn't
+// block on the network; FlushSentry should be called before the program exits so pending events aren't
+// lost. It returns ErrSentryFlushTimeout if events are still pending after timeout.
+func (l *Logger) FlushSentry(timeout time.Duration) error {
+	select {
+	case <-l.sentryDone():
+		return nil
+	case <-time.After(timeout):
+		return ErrSentryFlushTimeout
+	}
+}
+
+// sentryDone returns a channel that is closed once the events the Logger has sent to Sentry have been
+// delivered.
+func (l *Logger) sentryDone() <-chan struct{} {
+	if l.sentryPending == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return l.sentryPending.idle()
+}
+
+// sentryCounter counts the events a Logger, and the Loggers copied from it, have sent to Sentry that are
+// still being delivered. Unlike a sync.WaitGroup, events can be added while something is waiting for
+// them, and waiting doesn't tie up a goroutine that outlives the wait.
+type sentryCounter struct {
+	mu      sync.Mutex
+	pending int
+	// done is closed while no events are pending, and replaced when one is added.
+	done chan struct{}
+}
+
+// newSentryCounter returns a sentryCounter with no events pending.
+func newSentryCounter() *sentryCounter {
+	c := &sentryCounter{done: make(chan struct{})}
+	close(c.done)
+	return c
+}
+
+// add counts an event being delivered.
+func (c *sentryCounter) add() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == 0 {
+		c.done = make(chan struct{})
+	}
+	c.pending++
+}
+
+// finish counts an event as delivered, or failed.
+func (c *sentryCounter) finish() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending--
+	if c.pending == 0 {
+		close(c.done)
+	}
+}
+
+// idle returns a channel that is closed once no events are pending.
+func (c *sentryCounter) idle() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done
+}
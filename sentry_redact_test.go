@@ -0,0 +1,102 @@
+package logging_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DramaFever/go-logging"
+	"github.com/DramaFever/go-logging/logtest"
+	"github.com/DramaFever/raven-go"
+)
+
+func TestRedactSentry(t *testing.T) {
+	log, err := logging.New(logging.DebugLvl, ioutil.Discard, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	sentry, err := logtest.NewSentryRecorder(log)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.SetRedactPatterns(logging.DefaultRedactPatterns()...).
+		WithSentryExtra(map[string]interface{}{"auth": "Bearer abc.def"})
+	log.Errorf("charging %s failed: %v", "4111 1111 1111 1111", errors.New("declined 4111111111111111"))
+	if err := log.FlushSentry(time.Second); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	sentry.AssertSent(t, raven.ERROR, "charging "+logging.Redacted+" failed")
+	for _, p := range sentry.All() {
+		for _, i := range p.Interfaces {
+			switch v := i.(type) {
+			case *raven.Message:
+				if v.Message != "charging %s failed: %v" || v.Params[0] != logging.Redacted {
+					t.Errorf("Expected the params to be redacted, got %+v instead\n", v)
+				}
+			case *raven.Exception:
+				if strings.Contains(v.Value, "4111") {
+					t.Errorf("Expected the error to be redacted, got '%s' instead\n", v.Value)
+				}
+			}
+		}
+		if p.Extra["auth"] != logging.Redacted {
+			t.Errorf("Expected the extra context to be redacted, got %+v instead\n", p.Extra)
+		}
+	}
+}
+
+func TestRedactSentryRequest(t *testing.T) {
+	log, err := logging.New(logging.DebugLvl, ioutil.Discard, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	sentry, err := logtest.NewSentryRecorder(log)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	req, err := http.NewRequest("GET", "http://example.com/pay?card=4111111111111111&page=2", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	req.Header.Set("Authorization", "Bearer abc.def")
+	req.Header.Set("Cookie", "session=s3cret; theme=dark")
+	log = log.SetRedactPatterns(logging.DefaultRedactPatterns()...).SetRedactor(func(key, value string) string {
+		if key == "session" {
+			return logging.Redacted
+		}
+		return value
+	})
+	log.Errorf("request failed: %v", req)
+	log.AddMeta(raven.NewHttp(req)).Error("request failed")
+	if err := log.FlushSentry(time.Second); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	requests := 0
+	for _, p := range sentry.All() {
+		for _, i := range p.Interfaces {
+			h, ok := i.(*raven.Http)
+			if !ok {
+				continue
+			}
+			requests++
+			if h.Headers["Authorization"] != logging.Redacted {
+				t.Errorf("Expected the Authorization header to be redacted, got '%s' instead\n", h.Headers["Authorization"])
+			}
+			if h.Cookies != "session="+logging.Redacted+"; theme=dark" {
+				t.Errorf("Expected the session cookie to be redacted, got '%s' instead\n", h.Cookies)
+			}
+			if strings.Contains(h.Query, "4111") || !strings.Contains(h.Query, "page=2") {
+				t.Errorf("Expected the card number to be redacted from the query, got '%s' instead\n", h.Query)
+			}
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d instead\n", requests)
+	}
+	if req.Header.Get("Authorization") != "Bearer abc.def" {
+		t.Error("Expected the request itself to be unchanged")
+	}
+}
@@ -0,0 +1,318 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DramaFever/raven-go"
+)
+
+const (
+	defaultSentryQueueMaxEvents     = 1000
+	defaultSentryQueueRetryInterval = 30 * time.Second
+	defaultSentryQueueMaxAttempts   = 10
+)
+
+// SentryQueueConfig controls how a Logger queues Sentry events that could not be sent. The zero value is
+// usable, and gives the defaults described on each field.
+type SentryQueueConfig struct {
+	// MaxEvents is the number of events the queue holds. Once it is full, the oldest event is dropped to
+	// make room for each new one. It defaults to 1000.
+	MaxEvents int
+	// RetryInterval is how often sending the queued events is retried. It defaults to 30 seconds.
+	RetryInterval time.Duration
+	// MaxAttempts is the number of times a queued event is retried before it is dropped, so an event
+	// that keeps failing doesn't hold up the events behind it. It defaults to 10.
+	MaxAttempts int
+	// Path, if set, is a file the queue is kept in, so queued events survive a restart. Events left in it
+	// are loaded, and sent once Sentry can be reached.
+	//
+	// Each event is saved along with the auth header it is sent with, which holds the key and secret of
+	// the Sentry DSN. The file is created readable only by its owner, and should be protected like the DSN
+	// itself.
+	Path string
+}
+
+// queuedEvent is a Sentry event waiting to be sent.
+type queuedEvent struct {
+	URL        string          `json:"url"`
+	AuthHeader string          `json:"auth"`
+	Packet     json.RawMessage `json:"packet"`
+	Attempts   int             `json:"attempts,omitempty"`
+
+	packet *raven.Packet
+}
+
+// sentryQueue is a raven.Transport that queues the events its wrapped Transport fails to send, and
+// retries them in the background until they are sent.
+type sentryQueue struct {
+	next   raven.Transport
+	config SentryQueueConfig
+
+	mu     sync.Mutex
+	events []*queuedEvent
+
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// SetSentryQueue makes the Logger queue the Sentry events it fails to send, like during a network
+// partition, instead of dropping them. Queued events are retried every RetryInterval, and all of them are
+// sent as soon as Sentry can be reached again. The queue is bounded by MaxEvents, and drops its oldest
+// events when full, and events are dropped once they have been retried MaxAttempts times. Events Sentry
+// rejects, with a 4xx status other than 429 Too Many Requests, are never queued, since sending them
+// again won't help. If config has a Path, the queue is kept in that file, so events survive a restart;
+// without one, events still queued when the Logger is closed are lost.
+//
+// The queue wraps the Logger's current Sentry transport, so SetSentryTransport should be called first, if
+// at all. It returns an error if events left in config.Path can't be read. It does nothing if Sentry has
+// not been configured.
+func (l *Logger) SetSentryQueue(config SentryQueueConfig) (*Logger, error) {
+	if l.sentry == nil {
+		return l, nil
+	}
+	if config.MaxEvents <= 0 {
+		config.MaxEvents = defaultSentryQueueMaxEvents
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaultSentryQueueRetryInterval
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultSentryQueueMaxAttempts
+	}
+	q := &sentryQueue{
+		next:    l.sentry.Transport,
+		config:  config,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if err := q.load(); err != nil {
+		return l, err
+	}
+	l.sentry.Transport = q
+	go q.run()
+	return l, nil
+}
+
+// Send sends packet, unless earlier events are still queued, in which case Sentry is assumed to be
+// unreachable. If packet is not sent, it is queued, and Send only returns an error if it could not be
+// saved to the queue's file, or if Sentry rejected it.
+func (q *sentryQueue) Send(url, authHeader string, packet *raven.Packet) error {
+	q.mu.Lock()
+	pending := len(q.events) > 0
+	q.mu.Unlock()
+	if !pending {
+		err := q.next.Send(url, authHeader, packet)
+		if err == nil || !sentryRetryable(err) {
+			return err
+		}
+	}
+
+	body, err := packet.JSON()
+	if err != nil {
+		return err
+	}
+	e := &queuedEvent{URL: url, AuthHeader: authHeader, Packet: body, packet: packet}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.events = append(q.events, e)
+	if over := len(q.events) - q.config.MaxEvents; over > 0 {
+		q.events = append(q.events[:0], q.events[over:]...)
+		return q.save()
+	}
+	return q.appendSaved(e)
+}
+
+// Close stops retrying queued events.
+func (q *sentryQueue) Close() error {
+	q.once.Do(func() {
+		close(q.stop)
+	})
+	<-q.stopped
+	return nil
+}
+
+// run retries the queued events every RetryInterval, until the queue is closed.
+func (q *sentryQueue) run() {
+	defer close(q.stopped)
+	ticker := time.NewTicker(q.config.RetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			q.flush()
+		case <-q.stop:
+			return
+		}
+	}
+}
+
+// flush sends queued events, oldest first, until one fails in a way that may be temporary, or none are
+// left. Events that are rejected, or have run out of attempts, are dropped.
+func (q *sentryQueue) flush() {
+	changed := false
+	for {
+		q.mu.Lock()
+		if len(q.events) == 0 {
+			q.mu.Unlock()
+			break
+		}
+		e := q.events[0]
+		q.mu.Unlock()
+
+		err := q.next.Send(e.URL, e.AuthHeader, e.packet)
+		changed = true
+		q.mu.Lock()
+		if err != nil && sentryRetryable(err) {
+			e.Attempts++
+			if e.Attempts < q.config.MaxAttempts {
+				q.mu.Unlock()
+				break
+			}
+		}
+		// The event may have been dropped to make room while it was being sent.
+		if len(q.events) > 0 && q.events[0] == e {
+			q.events = q.events[1:]
+		}
+		q.mu.Unlock()
+	}
+	if changed {
+		q.mu.Lock()
+		q.save()
+		q.mu.Unlock()
+	}
+}
+
+// sentryStatusPattern matches the HTTP status in the errors returned by raven's HTTP transport.
+var sentryStatusPattern = regexp.MustCompile(`http status (\d{3})`)
+
+// sentryRetryable reports whether sending an event that failed with err might succeed later. Sentry
+// rejecting the event, with a 4xx status other than 429 Too Many Requests, is permanent; network errors,
+// 5xx statuses, rate limiting, and the errors of other transports are not.
+func sentryRetryable(err error) bool {
+	m := sentryStatusPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return true
+	}
+	status, _ := strconv.Atoi(m[1])
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// save writes the queued events to the queue's file, if it has one, replacing its contents. The caller
+// must hold q.mu.
+func (q *sentryQueue) save() error {
+	if q.config.Path == "" {
+		return nil
+	}
+	tmp := q.config.Path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range q.events {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := combineErrors(w.Flush(), f.Close()); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.config.Path)
+}
+
+// appendSaved adds e to the end of the queue's file, if it has one, so queuing an event doesn't rewrite
+// the whole file. The caller must hold q.mu.
+func (q *sentryQueue) appendSaved(e *queuedEvent) error {
+	if q.config.Path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(q.config.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	return combineErrors(json.NewEncoder(f).Encode(e), f.Close())
+}
+
+// load reads the events left in the queue's file, if it has one and it exists, keeping the newest
+// MaxEvents of them.
+func (q *sentryQueue) load() error {
+	if q.config.Path == "" {
+		return nil
+	}
+	f, err := os.Open(q.config.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		e := &queuedEvent{}
+		if err := dec.Decode(e); err != nil {
+			return err
+		}
+		if e.packet, err = decodePacket(e.Packet); err != nil {
+			return err
+		}
+		q.events = append(q.events, e)
+	}
+	if over := len(q.events) - q.config.MaxEvents; over > 0 {
+		q.events = q.events[over:]
+	}
+	return nil
+}
+
+// rawInterface is a Sentry interface loaded from the queue's file, which is sent exactly as it was saved.
+type rawInterface struct {
+	class string
+	data  json.RawMessage
+}
+
+func (r rawInterface) Class() string                { return r.class }
+func (r rawInterface) MarshalJSON() ([]byte, error) { return r.data, nil }
+
+// packetKeys are the JSON keys of the fields of a raven.Packet. Every other key in a packet's JSON is one
+// of its interfaces.
+var packetKeys = func() map[string]bool {
+	keys := map[string]bool{}
+	t := reflect.TypeOf(raven.Packet{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
+// decodePacket turns body, the JSON of a packet, back into a raven.Packet whose JSON is the same.
+func decodePacket(body json.RawMessage) (*raven.Packet, error) {
+	packet := &raven.Packet{}
+	if err := json.Unmarshal(body, packet); err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(body, &all); err != nil {
+		return nil, err
+	}
+	for k, v := range all {
+		if !packetKeys[k] {
+			packet.Interfaces = append(packet.Interfaces, rawInterface{class: k, data: v})
+		}
+	}
+	return packet, nil
+}
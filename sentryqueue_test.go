@@ -0,0 +1,215 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DramaFever/raven-go"
+)
+
+// toggleTransport is a raven.Transport that fails while down is set, and records the packets it sends
+// otherwise.
+type toggleTransport struct {
+	sync.Mutex
+	down    bool
+	packets []*raven.Packet
+}
+
+func (t *toggleTransport) Send(url, authHeader string, packet *raven.Packet) error {
+	t.Lock()
+	defer t.Unlock()
+	if t.down {
+		return errors.New("network is unreachable")
+	}
+	t.packets = append(t.packets, packet)
+	return nil
+}
+
+func (t *toggleTransport) setDown(down bool) {
+	t.Lock()
+	defer t.Unlock()
+	t.down = down
+}
+
+func newQueueTestLogger(t *testing.T, transport raven.Transport, config SentryQueueConfig) (*Logger, *sentryQueue) {
+	log, err := New(DebugLvl, ioutil.Discard, testSentryDSN, nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if _, err := log.SetSentryTransport(transport).SetSentryQueue(config); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	return log, log.sentry.Transport.(*sentryQueue)
+}
+
+func TestSentryQueue(t *testing.T) {
+	transport := &toggleTransport{down: true}
+	log, q := newQueueTestLogger(t, transport, SentryQueueConfig{MaxEvents: 2, RetryInterval: time.Hour})
+	defer log.Close()
+
+	for _, msg := range []string{"first", "second", "third"} {
+		log.Error(msg)
+		log.FlushSentry(time.Second)
+	}
+	if len(q.events) != 2 {
+		t.Fatalf("Expected the queue to hold 2 events, got %d instead\n", len(q.events))
+	}
+
+	q.flush()
+	if len(transport.packets) != 0 {
+		t.Errorf("Expected no events to be sent while Sentry is down, got %d instead\n", len(transport.packets))
+	}
+
+	transport.setDown(false)
+	q.flush()
+	if len(q.events) != 0 || len(transport.packets) != 2 {
+		t.Fatalf("Expected both queued events to be sent, got %d sent and %d queued instead\n",
+			len(transport.packets), len(q.events))
+	}
+	if transport.packets[0].Message != "second\n" || transport.packets[1].Message != "third\n" {
+		t.Errorf("Expected the oldest event to be dropped, got %q and %q instead\n", transport.packets[0].Message,
+			transport.packets[1].Message)
+	}
+
+	log.Error("fourth")
+	log.FlushSentry(time.Second)
+	if len(q.events) != 0 || len(transport.packets) != 3 {
+		t.Errorf("Expected new events to be sent directly, got %d sent and %d queued instead\n",
+			len(transport.packets), len(q.events))
+	}
+}
+
+func TestSentryQueueRetry(t *testing.T) {
+	transport := &toggleTransport{down: true}
+	log, _ := newQueueTestLogger(t, transport, SentryQueueConfig{RetryInterval: 10 * time.Millisecond})
+	defer log.Close()
+	log.Error("retried")
+	log.FlushSentry(time.Second)
+	transport.setDown(false)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		transport.Lock()
+		sent := len(transport.packets)
+		transport.Unlock()
+		if sent == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the queued event to be retried")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSentryQueueSpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sentryqueue")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "queue")
+
+	down := &toggleTransport{down: true}
+	log, _ := newQueueTestLogger(t, down, SentryQueueConfig{Path: path, RetryInterval: time.Hour})
+	log.Error("survives a restart")
+	log.FlushSentry(time.Second)
+	log.Close()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if strings.Count(string(b), "\n") != 1 || !strings.Contains(string(b), "survives a restart") {
+		t.Fatalf("Expected the event to be saved, got %q instead\n", b)
+	}
+
+	up := &toggleTransport{}
+	log, q := newQueueTestLogger(t, up, SentryQueueConfig{Path: path, RetryInterval: time.Hour})
+	defer log.Close()
+	q.flush()
+	if len(up.packets) != 1 {
+		t.Fatalf("Expected the saved event to be sent, got %d events instead\n", len(up.packets))
+	}
+	sent, err := up.packets[0].JSON()
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if !strings.Contains(string(sent), "survives a restart") {
+		t.Errorf("Expected the saved event to be sent unchanged, got %s instead\n", sent)
+	}
+	if b, _ := ioutil.ReadFile(path); len(b) != 0 {
+		t.Errorf("Expected the file to be emptied, got %q instead\n", b)
+	}
+}
+
+// statusTransport is a raven.Transport that fails packets with the HTTP status status returns for them,
+// like raven's HTTP transport, and records the packets it sends.
+type statusTransport struct {
+	sync.Mutex
+	status  func(*raven.Packet) int
+	packets []*raven.Packet
+}
+
+func (t *statusTransport) Send(url, authHeader string, packet *raven.Packet) error {
+	t.Lock()
+	defer t.Unlock()
+	if status := t.status(packet); status != 200 {
+		return fmt.Errorf("raven: got http status %d", status)
+	}
+	t.packets = append(t.packets, packet)
+	return nil
+}
+
+func TestSentryQueueRejected(t *testing.T) {
+	transport := &statusTransport{status: func(*raven.Packet) int { return 400 }}
+	log, q := newQueueTestLogger(t, transport, SentryQueueConfig{MaxAttempts: 2, RetryInterval: time.Hour})
+	defer log.Close()
+	log.Error("rejected")
+	log.FlushSentry(time.Second)
+	if len(q.events) != 0 {
+		t.Fatalf("Expected a rejected event not to be queued, got %d queued instead\n", len(q.events))
+	}
+
+	transport.status = func(*raven.Packet) int { return 503 }
+	log.Error("too big")
+	log.Error("fine")
+	log.FlushSentry(time.Second)
+	if len(q.events) != 2 {
+		t.Fatalf("Expected unavailable events to be queued, got %d queued instead\n", len(q.events))
+	}
+	transport.Lock()
+	transport.status = func(p *raven.Packet) int {
+		if strings.HasPrefix(p.Message, "too big") {
+			return 413
+		}
+		return 200
+	}
+	transport.Unlock()
+	q.flush()
+	if len(q.events) != 0 || len(transport.packets) != 1 {
+		t.Fatalf("Expected the rejected event to be dropped and the next sent, got %d sent and %d queued instead\n",
+			len(transport.packets), len(q.events))
+	}
+
+	transport.Lock()
+	transport.status = func(*raven.Packet) int { return 503 }
+	transport.Unlock()
+	log.Error("unavailable")
+	log.FlushSentry(time.Second)
+	q.flush()
+	if len(q.events) != 1 {
+		t.Fatalf("Expected the event to stay queued after one attempt, got %d queued instead\n", len(q.events))
+	}
+	q.flush()
+	if len(q.events) != 0 {
+		t.Errorf("Expected the event to be dropped after MaxAttempts, got %d queued instead\n", len(q.events))
+	}
+}
@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// HandleLevelSignals changes the Logger's Level when the process receives one of the signals in levels,
+// to the Level it maps to. This lets operators raise the verbosity of a running process, say with
+// kill -USR1, without restarting it. It returns a function that stops handling the signals; after it is
+// called, the signals have their default behavior again.
+func (l *Logger) HandleLevelSignals(levels map[os.Signal]Level) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	for sig := range levels {
+		signal.Notify(ch, sig)
+	}
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				if lvl, ok := levels[sig]; ok {
+					l.SetLevel(lvl)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logging
+
+import (
+	"os"
+	"syscall"
+)
+
+// HandleDefaultLevelSignals makes SIGUSR1 set the Logger's Level to DebugLvl, and SIGUSR2 set it to
+// InfoLvl. See HandleLevelSignals for details.
+func (l *Logger) HandleDefaultLevelSignals() (stop func()) {
+	return l.HandleLevelSignals(map[os.Signal]Level{
+		syscall.SIGUSR1: DebugLvl,
+		syscall.SIGUSR2: InfoLvl,
+	})
+}
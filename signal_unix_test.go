@@ -0,0 +1,58 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logging
+
+import (
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// waitForLevel waits for the Logger's Level to become lvl, for at most a few seconds.
+func waitForLevel(log *Logger, lvl Level) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if log.GetLevel() == lvl {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
+
+func TestHandleDefaultLevelSignals(t *testing.T) {
+	log, err := New(WarnLvl, ioutil.Discard, "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	stop := log.HandleDefaultLevelSignals()
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	if !waitForLevel(log, DebugLvl) {
+		t.Errorf("Expected SIGUSR1 to set the level to %s, got %s instead\n", DebugLvl, log.GetLevel())
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	if !waitForLevel(log, InfoLvl) {
+		t.Errorf("Expected SIGUSR2 to set the level to %s, got %s instead\n", InfoLvl, log.GetLevel())
+	}
+	stop()
+	stop()
+
+	// Catch the signal here, so it doesn't kill the test process once the Logger stops handling it.
+	caught := make(chan os.Signal, 1)
+	signal.Notify(caught, syscall.SIGUSR1)
+	defer signal.Stop(caught)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	select {
+	case <-caught:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected to catch SIGUSR1")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if log.GetLevel() != InfoLvl {
+		t.Errorf("Expected the level to stay %s after stopping, got %s instead\n", InfoLvl, log.GetLevel())
+	}
+}
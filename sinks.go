@@ -0,0 +1,120 @@
+package logging
+
+import "io"
+
+// Sink is a destination for a Logger's entries, written in its own format. See SetSinks.
+type Sink struct {
+	Writer io.Writer
+	// Formatter renders the entries written to Writer. If it is nil, the Logger's Formatter is used.
+	Formatter Formatter
+}
+
+// sinks is the output of a Logger configured with SetSinks. Entries are formatted separately for each
+// Sink by writeSinks; whatever is written to sinks directly, like the lines written by Raw, is written to
+// every Sink as is.
+type sinks []Sink
+
+// Write writes p to every Sink, and returns the errors of any that fail.
+func (s sinks) Write(p []byte) (int, error) {
+	return s.WriteLevel(InfoLvl, p)
+}
+
+// WriteLevel writes p to every Sink, using WriteLevel for those that are LevelWriters.
+func (s sinks) WriteLevel(level Level, p []byte) (int, error) {
+	var errs []error
+	for _, sink := range s {
+		var err error
+		if lw, ok := sink.Writer.(LevelWriter); ok {
+			_, err = lw.WriteLevel(level, p)
+		} else {
+			_, err = sink.Writer.Write(p)
+		}
+		errs = append(errs, err)
+	}
+	return len(p), combineErrors(errs...)
+}
+
+// Flush flushes every Sink whose Writer has a Flush method.
+func (s sinks) Flush() error {
+	var errs []error
+	for _, sink := range s {
+		if flusher, ok := sink.Writer.(interface {
+			Flush() error
+		}); ok {
+			errs = append(errs, flusher.Flush())
+		}
+	}
+	return combineErrors(errs...)
+}
+
+// Sync syncs every Sink whose Writer has a Sync method.
+func (s sinks) Sync() error {
+	var errs []error
+	for _, sink := range s {
+		if syncer, ok := sink.Writer.(interface {
+			Sync() error
+		}); ok {
+			errs = append(errs, syncer.Sync())
+		}
+	}
+	return combineErrors(errs...)
+}
+
+// Close closes every Sink whose Writer is an io.Closer, even if closing an earlier one fails.
+func (s sinks) Close() error {
+	var errs []error
+	for _, sink := range s {
+		if closer, ok := sink.Writer.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return combineErrors(errs...)
+}
+
+// SetSinks makes the Logger write every entry to each of the Sinks, formatted with that Sink's Formatter,
+// in place of its output. For example, to write text to stdout and JSON to a file:
+//
+//	log.SetSinks(
+//		logging.Sink{Writer: os.Stdout, Formatter: logging.TextFormatter{}},
+//		logging.Sink{Writer: file, Formatter: logging.JSONFormatter{}},
+//	)
+//
+// Flush and Close flush and close each Sink's Writer, and a failure to write to one Sink doesn't stop the
+// entry being written to the others. SetOutput replaces the Sinks.
+func (l *Logger) SetSinks(s ...Sink) *Logger {
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	l.setOutput(append(sinks(nil), s...))
+	return l
+}
+
+// writeSinks formats entry for, and writes it to, each of the Sinks set by SetSinks.
+func (l *Logger) writeSinks(entry *Entry) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	l.flock.Lock()
+	defer l.flock.Unlock()
+	s, ok := l.out.(sinks)
+	if !ok {
+		// The output was replaced by SetOutput since write checked for Sinks.
+		if l.out == nil {
+			return nil
+		}
+		s = sinks{{Writer: l.out}}
+	}
+	var errs []error
+	for _, sink := range s {
+		formatter := sink.Formatter
+		if formatter == nil {
+			formatter = l.formatter
+		}
+		if formatter == nil {
+			formatter = TextFormatter{}
+		}
+		*buf = (*buf)[:0]
+		formatter.Format(buf, entry)
+		*buf = append(*buf, l.lineTerminator...)
+		errs = append(errs, l.emitTo(sink.Writer, entry.Level, *buf))
+	}
+	return combineErrors(errs...)
+}
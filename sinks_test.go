@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSetSinks(t *testing.T) {
+	var text bytes.Buffer
+	file := &failingCloser{}
+	log, err := NewWithOptions(WithSinks(
+		Sink{Writer: &text},
+		Sink{Writer: file, Formatter: JSONFormatter{}},
+	))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Infow("started", Int("port", 8080))
+	if !strings.Contains(text.String(), "[INFO]") || !strings.HasSuffix(text.String(), "started port=8080\n") {
+		t.Errorf("Expected a text line, got '%s' instead\n", text.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(file.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON line, got '%s' instead\n", file.String())
+	}
+	if entry["msg"] != "started" || entry["port"] != float64(8080) {
+		t.Errorf("Expected the message and fields, got %+v instead\n", entry)
+	}
+
+	text.Reset()
+	file.Reset()
+	log.Raw(InfoLvl, "preformatted")
+	if text.String() != "preformatted\n" || file.String() != "preformatted\n" {
+		t.Errorf("Expected Raw to write to every sink, got '%s' and '%s' instead\n", text.String(), file.String())
+	}
+
+	if err := log.Close(); err == nil || err.Error() != "already closed" {
+		t.Errorf("Expected the file's error, got %+v instead\n", err)
+	}
+	if file.closes != 1 {
+		t.Errorf("Expected the file to be closed once, got %d instead\n", file.closes)
+	}
+}
+
+func TestSetSinksFailure(t *testing.T) {
+	var good bytes.Buffer
+	log, err := New(InfoLvl, &bytes.Buffer{}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetSinks(Sink{Writer: errWriter{err: errors.New("disk full")}}, Sink{Writer: &good})
+	log.Info("still written")
+	if !strings.HasSuffix(good.String(), "still written\n") {
+		t.Errorf("Expected the entry in the working sink, got '%s' instead\n", good.String())
+	}
+
+	var replaced bytes.Buffer
+	log.SetOutput(&replaced)
+	log.Info("replaced")
+	if !strings.HasSuffix(replaced.String(), "replaced\n") || strings.Contains(good.String(), "replaced") {
+		t.Errorf("Expected SetOutput to replace the sinks, got '%s' instead\n", replaced.String())
+	}
+
+	if _, err := NewWithOptions(WithSinks(Sink{})); err != ErrNilWriter {
+		t.Errorf("Expected ErrNilWriter, got %+v instead\n", err)
+	}
+}
@@ -0,0 +1,104 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler is a slog.Handler that writes records through a Logger, so code using log/slog keeps the
+// Logger's output format and Sentry routing. Attributes become Fields on the entry; attributes in a
+// group are rendered with the group names joined to their key by dots, like "request.id".
+type SlogHandler struct {
+	logger *Logger
+	fields Fields
+	prefix string
+}
+
+// NewSlogHandler returns a SlogHandler that writes through l.
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+// fromSlogLevel maps a slog.Level onto the closest Level.
+func fromSlogLevel(lvl slog.Level) Level {
+	switch {
+	case lvl < slog.LevelInfo:
+		return DebugLvl
+	case lvl < slog.LevelWarn:
+		return InfoLvl
+	case lvl < slog.LevelError:
+		return WarnLvl
+	default:
+		return ErrorLvl
+	}
+}
+
+// Enabled reports whether the Logger's Level includes records at lvl.
+func (h *SlogHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return h.logger.enabled(fromSlogLevel(lvl))
+}
+
+// Handle writes r through the Logger, applying its filters, sampling, package levels, and prefix as it
+// would to an entry logged directly. A record without a time is given the Logger's current time.
+// Records at slog.LevelWarn and above are sent to Sentry, if Sentry has been configured.
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	lvl := fromSlogLevel(r.Level)
+	fields := make(Fields, len(h.fields)+r.NumAttrs())
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(fields, h.prefix, a)
+		return true
+	})
+	typed := fieldList(fields)
+	err := h.logger.outputAt(-1, r.PC, r.Time, r.Message, lvl, typed)
+	if lvl == WarnLvl || lvl == ErrorLvl {
+		h.logger.toSentry("%s", []interface{}{r.Message}, lvl, typed)
+	}
+	return err
+}
+
+// WithAttrs returns a SlogHandler whose records always include attrs.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := make(Fields, len(h.fields)+len(attrs))
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	for _, a := range attrs {
+		addAttr(fields, h.prefix, a)
+	}
+	return &SlogHandler{logger: h.logger, fields: fields, prefix: h.prefix}
+}
+
+// WithGroup returns a SlogHandler that qualifies all subsequent attributes with name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// addAttr adds a to fields, flattening groups into dotted keys.
+func addAttr(fields Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			addAttr(fields, prefix, ga)
+		}
+		return
+	}
+	fields[prefix+a.Key] = a.Value.Any()
+}
@@ -0,0 +1,124 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromSlogLevel(t *testing.T) {
+	conversionTests := map[slog.Level]Level{
+		slog.LevelDebug - 4: DebugLvl,
+		slog.LevelDebug:     DebugLvl,
+		slog.LevelInfo:      InfoLvl,
+		slog.LevelInfo + 2:  InfoLvl,
+		slog.LevelWarn:      WarnLvl,
+		slog.LevelError:     ErrorLvl,
+		slog.LevelError + 4: ErrorLvl,
+	}
+	for in, out := range conversionTests {
+		result := fromSlogLevel(in)
+		if result != out {
+			t.Errorf("Expected %s to be %s, got %s instead", in, out, result)
+		}
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	logger := slog.New(NewSlogHandler(log))
+
+	type slogTest struct {
+		log      func()
+		expected string
+	}
+	slogTests := []slogTest{
+		{log: func() { logger.Debug("suppressed") }, expected: ""},
+		{log: func() { logger.Info("string", "name", "gopher") }, expected: "[INFO] %s: string name=gopher"},
+		{log: func() { logger.Info("int", "count", 42) }, expected: "[INFO] %s: int count=42"},
+		{log: func() { logger.Warn("bool", "ok", false) }, expected: "[WARN] %s: bool ok=false"},
+		{log: func() { logger.Error("duration", "took", 1500*time.Millisecond) }, expected: "[ERROR] %s: duration took=1.5s"},
+		{log: func() { logger.Error("error", "err", errors.New("no route")) }, expected: `[ERROR] %s: error err="no route"`},
+		{log: func() { logger.Info("group", slog.Group("req", "id", 7, "path", "/")) }, expected: "[INFO] %s: group req.id=7 req.path=/"},
+		{log: func() { logger.With("svc", "api").WithGroup("db").Info("nested", "table", "users") }, expected: "[INFO] %s: nested db.table=users svc=api"},
+	}
+	for pos, test := range slogTests {
+		buf.Reset()
+		test.log()
+		out := strings.TrimSuffix(buf.String(), "\n")
+		if test.expected == "" {
+			if out != "" {
+				t.Errorf("Expected test %d to be suppressed, got '%s'\n", pos, out)
+			}
+			continue
+		}
+		// strip the timestamp, and check the caller separately
+		out = out[strings.Index(out, " ")+1:]
+		caller := out[strings.Index(out, "] ")+2 : strings.Index(out, ": ")]
+		if !strings.Contains(caller, "slog_test.go:") {
+			t.Errorf("Expected caller to be slog_test.go, got '%s'\n", caller)
+		}
+		expected := strings.Replace(test.expected, "%s", caller, 1)
+		if out != expected {
+			t.Errorf("Expected test %d to be '%s', got '%s' instead\n", pos, expected, out)
+		}
+	}
+}
+
+func TestSlogHandlerOutput(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithFormatter(upperFormatter{}), WithPrefix("[svc] "))
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	now := time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC)
+	log.SetClock(func() time.Time { return now }).AddFilter(regexp.MustCompile("^noisy"), true)
+	handler := NewSlogHandler(log)
+
+	handler.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "noisy poll", 0))
+	handler.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "started", 0))
+	if buf.String() != "INFO: [svc] started\n" {
+		t.Errorf("Expected filters and the prefix to apply, got '%s' instead\n", buf.String())
+	}
+
+	hook := &lastEntry{}
+	log.AddHook(hook)
+	handler.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "untimed", 0))
+	if hook.entry == nil || !hook.entry.Time.Equal(now) || hook.entry.File != "???" {
+		t.Errorf("Expected the Logger's time and an unknown caller, got %+v instead\n", hook.entry)
+	}
+}
+
+// lastEntry is a Hook that keeps the last Entry it saw.
+type lastEntry struct {
+	entry *Entry
+}
+
+func (h *lastEntry) Fire(e *Entry) {
+	copied := *e
+	h.entry = &copied
+}
+
+func TestSlogHandlerSentry(t *testing.T) {
+	log, transport := newSentryTestLogger(t)
+	slog.New(NewSlogHandler(log)).Error("100% done", "job", "backup")
+	log.FlushSentry(time.Second)
+	if len(transport.packets) != 1 || transport.packets[0].Message != "100% done" {
+		t.Fatalf("Expected '100%% done' to be sent as is, got %+v instead\n", transport.packets)
+	}
+	if transport.packets[0].Extra["job"] != "backup" {
+		t.Errorf("Expected the attributes in the extra context, got %+v instead\n", transport.packets[0].Extra)
+	}
+}
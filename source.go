@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultSentryContextLines is the number of lines of source around each stack frame sent to Sentry,
+// unless SetSourceContext asks for more on errors.
+const defaultSentryContextLines = 2
+
+// sourceFiles caches the lines of the source files read for source context. Files that can't be read are
+// cached as nil, so they aren't tried again.
+var sourceFiles = struct {
+	sync.Mutex
+	lines map[string][][]byte
+}{lines: map[string][][]byte{}}
+
+// SetSourceContext makes the Logger attach the given number of lines of source code, before and after
+// the caller's line, to ERROR entries. The lines are sent with the frames of the Sentry event's stack
+// trace, and, if inOutput is set, also written after the message, with the caller's line marked:
+//
+//	2015-07-02T13:28:42 [ERROR] /my/test/file.go:145: query failed
+//	  144 |	rows, err := db.Query(q)
+//	> 145 |	log.Error("query failed")
+//	  146 |	return err
+//
+// Source files are read from disk the first time they are needed, and cached. Files that aren't on disk,
+// like in a container that only holds the binary, are skipped. Pass 0 to turn source context off again.
+func (l *Logger) SetSourceContext(lines int, inOutput bool) *Logger {
+	if lines < 0 {
+		lines = 0
+	}
+	l.sourceLines = lines
+	l.sourceInOutput = inOutput && lines > 0
+	return l
+}
+
+// sentryContextLines returns the number of lines of source to send around each frame of a Sentry event
+// at lvl.
+func (l *Logger) sentryContextLines(lvl Level) int {
+	if lvl == ErrorLvl && l.sourceLines > 0 {
+		return l.sourceLines
+	}
+	return defaultSentryContextLines
+}
+
+// sourceContext returns n lines of file on either side of line, each on a new line prefixed with its
+// number, and with line itself marked. It returns "" if file can't be read or doesn't have line.
+func sourceContext(file string, line, n int) string {
+	lines := sourceLines(file)
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	first, last := line-n, line+n
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(strconv.Itoa(last))
+	var b strings.Builder
+	for i := first; i <= last; i++ {
+		b.WriteByte('\n')
+		if i == line {
+			b.WriteString("> ")
+		} else {
+			b.WriteString("  ")
+		}
+		num := strconv.Itoa(i)
+		b.WriteString(strings.Repeat(" ", width-len(num)))
+		b.WriteString(num)
+		b.WriteString(" | ")
+		b.Write(bytes.TrimRight(lines[i-1], "\r"))
+	}
+	return b.String()
+}
+
+// sourceLines returns the lines of file, reading it if it isn't cached, or nil if it can't be read.
+func sourceLines(file string) [][]byte {
+	sourceFiles.Lock()
+	defer sourceFiles.Unlock()
+	lines, ok := sourceFiles.lines[file]
+	if ok {
+		return lines
+	}
+	if b, err := ioutil.ReadFile(file); err == nil {
+		lines = bytes.Split(b, []byte("\n"))
+	}
+	sourceFiles.lines[file] = lines
+	return lines
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "source")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "main.go")
+	src := "package main\n\nfunc main() {\n\tpanic(\"oops\")\n}\n"
+	if err := ioutil.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	tests := []struct {
+		line, n  int
+		expected string
+	}{
+		{4, 1, "\n  3 | func main() {\n> 4 | \tpanic(\"oops\")\n  5 | }"},
+		{1, 2, "\n> 1 | package main\n  2 | \n  3 | func main() {"},
+		{100, 2, ""},
+	}
+	for _, test := range tests {
+		if got := sourceContext(file, test.line, test.n); got != test.expected {
+			t.Errorf("Expected %q for line %d, got %q instead\n", test.expected, test.line, got)
+		}
+	}
+
+	if got := sourceContext(filepath.Join(dir, "missing.go"), 4, 2); got != "" {
+		t.Errorf("Expected a missing file to be skipped, got %q instead\n", got)
+	}
+}
+
+func TestSetSourceContext(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.SetSourceContext(1, true)
+
+	log.Warn("no source")
+	if strings.Contains(buf.String(), " | ") {
+		t.Errorf("Expected no source context for a warning, got %q instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log.Error("with source")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected the entry and 3 lines of source, got %q instead\n", buf.String())
+	}
+	if !strings.HasPrefix(lines[2], "> ") || !strings.HasSuffix(lines[2], "log.Error(\"with source\")") {
+		t.Errorf("Expected the caller's line to be marked, got %q instead\n", lines[2])
+	}
+
+	buf.Reset()
+	log.SetSourceContext(1, false).Error("without output")
+	if strings.Contains(buf.String(), " | ") {
+		t.Errorf("Expected no source context in the output, got %q instead\n", buf.String())
+	}
+	if log.sentryContextLines(ErrorLvl) != 1 || log.sentryContextLines(WarnLvl) != defaultSentryContextLines {
+		t.Error("Expected the source context to only apply to errors sent to Sentry")
+	}
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"strconv"
+	"time"
+)
+
+// StackdriverFormatter renders an Entry as a single-line JSON object in the structured logging format
+// Google Cloud Logging reads from a container's output, like
+//
+//	{"severity":"WARNING","message":"message","time":"2015-07-02T13:28:42Z","logging.googleapis.com/sourceLocation":{"file":"/my/test/file.go","line":"145"},"key":"value"}
+//
+// The severity is one of Cloud Logging's DEBUG, INFO, WARNING, or ERROR, so severity filters work, and
+// the caller is written as the entry's source location. Fields are written after the standard keys,
+// sorted by key, and end up in the entry's jsonPayload.
+type StackdriverFormatter struct{}
+
+// stackdriverSeverity returns the Cloud Logging severity that corresponds to the Level.
+func (l Level) stackdriverSeverity() string {
+	switch l {
+	case DebugLvl:
+		return "DEBUG"
+	case InfoLvl:
+		return "INFO"
+	case WarnLvl:
+		return "WARNING"
+	default:
+		return "ERROR"
+	}
+}
+
+// Format appends e to buf as a Cloud Logging JSON object.
+func (f StackdriverFormatter) Format(buf *[]byte, e *Entry) {
+	*buf = append(*buf, `{"severity":"`...)
+	*buf = append(*buf, e.Level.stackdriverSeverity()...)
+	*buf = append(*buf, `","message":`...)
+	appendJSONString(buf, e.Message)
+	*buf = append(*buf, `,"time":"`...)
+	*buf = e.Time.AppendFormat(*buf, time.RFC3339Nano)
+	*buf = append(*buf, '"')
+	if e.File != "" {
+		*buf = append(*buf, `,"logging.googleapis.com/sourceLocation":{"file":`...)
+		appendJSONString(buf, e.File)
+		// Cloud Logging encodes the line, an int64, as a string.
+		*buf = append(*buf, `,"line":"`...)
+		*buf = strconv.AppendInt(*buf, int64(e.Line), 10)
+		*buf = append(*buf, `"}`...)
+	}
+	if e.Goroutine != 0 {
+		*buf = append(*buf, `,"goroutine":`...)
+		*buf = strconv.AppendUint(*buf, e.Goroutine, 10)
+	}
+	encodeEntryFields(JSONEncoder{}, buf, e)
+	*buf = append(*buf, '}')
+}
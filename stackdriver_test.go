@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStackdriverFormatter(t *testing.T) {
+	var buf []byte
+	StackdriverFormatter{}.Format(&buf, &Entry{
+		Time:    time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC),
+		Level:   WarnLvl,
+		File:    "/my/test/file.go",
+		Line:    145,
+		Message: "disk \"almost\" full",
+		Fields:  Fields{"user": "bob", "count": 3},
+	})
+	expected := `{"severity":"WARNING","message":"disk \"almost\" full","time":"2015-07-02T13:28:42Z",` +
+		`"logging.googleapis.com/sourceLocation":{"file":"/my/test/file.go","line":"145"},"count":3,"user":"bob"}`
+	if string(buf) != expected {
+		t.Errorf("Expected %s, got %s instead\n", expected, buf)
+	}
+}
+
+func TestLevelStackdriverSeverity(t *testing.T) {
+	for level, expected := range map[Level]string{DebugLvl: "DEBUG", InfoLvl: "INFO", WarnLvl: "WARNING", ErrorLvl: "ERROR"} {
+		if got := level.stackdriverSeverity(); got != expected {
+			t.Errorf("Expected %s to map to %s, got %s instead\n", level, expected, got)
+		}
+	}
+}
+
+func TestWithStackdriver(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(WithOutput(&buf), WithStackdriver())
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log.Warn("valid JSON")
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Expected valid JSON, got %s instead: %+v\n", buf.String(), err)
+	}
+	if got["severity"] != "WARNING" || got["message"] != "valid JSON" {
+		t.Errorf("Expected a WARNING with the message, got %v instead\n", got)
+	}
+}
@@ -0,0 +1,59 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logging
+
+import (
+	"log/syslog"
+)
+
+// syslogWriter is a LevelWriter that sends each entry to syslog with the severity of its Level.
+type syslogWriter struct {
+	w *syslog.Writer
+}
+
+// LogToSyslog creates a new Logger that writes to a syslog daemon, using the user facility and the
+// severity that corresponds to each entry's Level. If network is empty, it connects to the local syslog
+// daemon; otherwise network and raddr are passed to net.Dial. The tag is written with every message,
+// and is usually the name of the program; if it is empty, os.Args[0] is used. The connection is closed
+// by the Logger's Close method.
+//
+// If sentry is non-empty, it will be used as a DSN to connect to a Sentry error collector. The sentryTags
+// are a key/value mapping that will be applied to your Sentry errors. You can use them to set things like
+// the version of your software running, etc.
+func LogToSyslog(level Level, network, raddr, tag string, sentry string, sentryTags map[string]string) (*Logger, error) {
+	w, err := syslog.Dial(network, raddr, syslog.LOG_USER|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return New(level, syslogWriter{w: w}, sentry, sentryTags)
+}
+
+// Write sends p to syslog with the informational severity.
+func (s syslogWriter) Write(p []byte) (int, error) {
+	return s.WriteLevel(InfoLvl, p)
+}
+
+// WriteLevel sends p to syslog with the severity that corresponds to level.
+func (s syslogWriter) WriteLevel(level Level, p []byte) (int, error) {
+	var err error
+	switch level {
+	case DebugLvl:
+		err = s.w.Debug(string(p))
+	case InfoLvl:
+		err = s.w.Info(string(p))
+	case WarnLvl:
+		err = s.w.Warning(string(p))
+	default:
+		err = s.w.Err(string(p))
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection to syslog.
+func (s syslogWriter) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,80 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logging
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogToSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer conn.Close()
+	log, err := LogToSyslog(DebugLvl, "udp", conn.LocalAddr().String(), "test", "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer log.Close()
+
+	cases := []struct {
+		emit     func(...interface{})
+		priority string
+	}{
+		{log.Debug, "<15>"},
+		{log.Info, "<14>"},
+		{log.Warn, "<12>"},
+		{log.Error, "<11>"},
+	}
+	buf := make([]byte, 2048)
+	for _, c := range cases {
+		if c.priority == "<15>" && !debugEnabled {
+			continue
+		}
+		c.emit("hello syslog")
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v\n", err)
+		}
+		msg := string(buf[:n])
+		if !strings.HasPrefix(msg, c.priority) {
+			t.Errorf("Expected message to start with %s, got '%s' instead\n", c.priority, msg)
+		}
+		if !strings.Contains(msg, " test[") || !strings.Contains(msg, "hello syslog") {
+			t.Errorf("Expected message to contain the tag and text, got '%s' instead\n", msg)
+		}
+	}
+}
+
+func TestSyslogFlushInterval(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer conn.Close()
+	log, err := LogToSyslog(InfoLvl, "udp", conn.LocalAddr().String(), "test", "", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	defer log.Close()
+	log.SetFlushInterval(time.Hour)
+	if _, ok := log.out.(*BatchWriter); ok {
+		t.Fatal("Expected the syslog output not to be buffered")
+	}
+	log.Warn("hello syslog")
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<12>") {
+		t.Errorf("Expected the entry's severity, got '%s' instead\n", msg)
+	}
+}
@@ -0,0 +1,36 @@
+package logging
+
+import "time"
+
+// DurationField is the field a Logger returned by Timer.Stop logs the elapsed time in.
+const DurationField = "duration"
+
+// Timer measures how long something takes, so it can be logged. See StartTimer.
+type Timer struct {
+	log   *Logger
+	start time.Time
+}
+
+// StartTimer starts a Timer, which logs the time since it was started in a "duration" field:
+//
+//	t := log.StartTimer()
+//	...
+//	t.Stop().Info("done")
+//
+// The time is measured with the monotonic clock, so it isn't thrown off if the wall clock is changed while
+// the Timer runs. If the Logger's clock has been replaced with SetClock, that clock is used instead.
+func (l *Logger) StartTimer() Timer {
+	return Timer{log: l, start: l.clock()}
+}
+
+// Elapsed returns the time since the Timer was started.
+func (t Timer) Elapsed() time.Duration {
+	return t.log.clock().Sub(t.start)
+}
+
+// Stop returns a child of the Logger the Timer was started from, with the time since it was started in
+// the "duration" field. It is written like any time.Duration field, so it is formatted according to the
+// Logger's duration unit; see SetDurationUnit.
+func (t Timer) Stop() *Logger {
+	return t.log.Child(Fields{DurationField: t.Elapsed()})
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimer(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(InfoLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	now := time.Date(2015, time.July, 2, 13, 28, 42, 0, time.UTC)
+	log.SetClock(func() time.Time { return now })
+	timer := log.StartTimer()
+	now = now.Add(1500 * time.Millisecond)
+	if timer.Elapsed() != 1500*time.Millisecond {
+		t.Errorf("Expected 1.5s elapsed, got %s instead\n", timer.Elapsed())
+	}
+	timer.Stop().Info("done")
+	if !strings.HasSuffix(buf.String(), "done duration=1.5s\n") {
+		t.Errorf("Expected the duration in the entry, got '%s' instead\n", buf.String())
+	}
+
+	buf.Reset()
+	log.SetDurationUnit(time.Millisecond)
+	timer.Stop().Info("done")
+	if !strings.HasSuffix(buf.String(), "done duration=1500\n") {
+		t.Errorf("Expected the duration in milliseconds, got '%s' instead\n", buf.String())
+	}
+
+	log.SetClock(nil)
+	timer = log.StartTimer()
+	time.Sleep(time.Millisecond)
+	if elapsed := timer.Elapsed(); elapsed < time.Millisecond {
+		t.Errorf("Expected at least 1ms elapsed, got %s instead\n", elapsed)
+	}
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	// TraceIDField is the field a Logger returned by WithContext holds the trace ID in.
+	TraceIDField = "trace_id"
+	// SpanIDField is the field a Logger returned by WithContext holds the span ID in.
+	SpanIDField = "span_id"
+
+	traceParentKey = "github.com/DramaFever/go-logging#traceparent"
+)
+
+// traceIDs holds the IDs of the trace and span a Context belongs to.
+type traceIDs struct {
+	traceID string
+	spanID  string
+}
+
+// contextExtractors add fields from a Context to the Fields of a Logger returned by WithContext.
+var contextExtractors = []func(ctx context.Context, fields Fields){
+	traceParentFields,
+	b3Fields,
+}
+
+// WithContext returns a child of the Logger with fields from ctx, so every line it logs can be tied to
+// the request ctx belongs to. If ctx holds a W3C traceparent, saved with ContextWithTraceParent, or B3
+// headers, saved with ContextWithB3, their trace and span IDs are added as the trace_id and span_id
+// fields; the traceparent is used if there are both. If ctx holds nothing to add, the Logger itself is
+// returned.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	fields := Fields{}
+	for _, extract := range contextExtractors {
+		extract(ctx, fields)
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	return l.Child(fields)
+}
+
+// ContextWithTraceParent returns a copy of ctx holding the trace and span IDs from header, the value of
+// a W3C traceparent header, like "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". If header
+// is empty or malformed, ctx is returned unchanged, so requests without a trace are logged as usual.
+func ContextWithTraceParent(ctx context.Context, header string) context.Context {
+	traceID, spanID, ok := ParseTraceParent(header)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, traceParentKey, traceIDs{traceID: traceID, spanID: spanID})
+}
+
+// ParseTraceParent returns the trace and span IDs from header, the value of a W3C traceparent header. ok
+// is false if header is not a valid traceparent, or if either ID is all zeroes, which the specification
+// reserves as invalid.
+func ParseTraceParent(header string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return "", "", false
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	// Later versions may add fields, but version 00 has exactly four.
+	if !isLowerHex(version, 2) || version == "ff" || version == "00" && len(parts) != 4 {
+		return "", "", false
+	}
+	if !isLowerHex(traceID, 32) || !isLowerHex(spanID, 16) || !isLowerHex(flags, 2) {
+		return "", "", false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// isLowerHex reports whether s is n lower-case hexadecimal digits.
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(hexDigits, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// traceParentFields adds the IDs of the traceparent in ctx, if there is one, to fields.
+func traceParentFields(ctx context.Context, fields Fields) {
+	ids, ok := ctx.Value(traceParentKey).(traceIDs)
+	if !ok {
+		return
+	}
+	fields[TraceIDField] = ids.traceID
+	fields[SpanIDField] = ids.spanID
+}
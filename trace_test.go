@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	parseTests := []struct {
+		header          string
+		traceID, spanID string
+		ok              bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+		{" 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00 ", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true},
+		{"", "", "", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "", "", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "", "", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", "", "", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", "", "", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", "", "", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", "", "", false},
+	}
+	for _, test := range parseTests {
+		traceID, spanID, ok := ParseTraceParent(test.header)
+		if traceID != test.traceID || spanID != test.spanID || ok != test.ok {
+			t.Errorf("Expected %q to parse to %q, %q, %t, got %q, %q, %t instead\n", test.header, test.traceID,
+				test.spanID, test.ok, traceID, spanID, ok)
+		}
+	}
+}
+
+func TestWithContextTraceParent(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if got := log.WithContext(context.Background()); got != log {
+		t.Error("Expected the Logger itself for a Context without a traceparent")
+	}
+	ctx := ContextWithTraceParent(context.Background(), "not a traceparent")
+	if got := log.WithContext(ctx); got != log {
+		t.Error("Expected the Logger itself for a Context with a malformed traceparent")
+	}
+
+	ctx = ContextWithTraceParent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	log.WithContext(ctx).Info("traced")
+	if !strings.HasSuffix(buf.String(), "traced span_id=00f067aa0ba902b7 trace_id=4bf92f3577b34da6a3ce929d0e0e4736\n") {
+		t.Errorf("Expected trace fields, got %q instead\n", buf.String())
+	}
+	if len(log.GetFields()) != 0 {
+		t.Errorf("Expected the parent Logger to be unchanged, got %v instead\n", log.GetFields())
+	}
+}
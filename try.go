@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"fmt"
+)
+
+// The Try methods behave like their counterparts without the Try prefix, but return any error
+// encountered writing the entry to the Logger's output instead of passing it to the error handler. They
+// are meant for code paths where a dropped log line is itself a problem, like audit logging. A nil
+// error is returned when the entry is suppressed by the Logger's Level.
+//
+// Errors sending messages to Sentry are not returned; only the write to the Logger's output is reported.
+
+// TryInfof is like Infof, but returns any error writing the entry.
+func (l *Logger) TryInfof(format string, msg ...interface{}) error {
+	if !l.enabled(InfoLvl) {
+		return nil
+	}
+	return l.tryLogf(format, InfoLvl, msg...)
+}
+
+// TryInfo is like Info, but returns any error writing the entry.
+func (l *Logger) TryInfo(msg ...interface{}) error {
+	if !l.enabled(InfoLvl) {
+		return nil
+	}
+	return l.tryLog(InfoLvl, msg...)
+}
+
+// TryWarnf is like Warnf, but returns any error writing the entry.
+func (l *Logger) TryWarnf(format string, msg ...interface{}) error {
+	if !l.enabled(WarnLvl) {
+		return nil
+	}
+	err := l.tryLogf(format, WarnLvl, msg...)
+	l.toSentry(format, msg, WarnLvl, nil)
+	return err
+}
+
+// TryWarn is like Warn, but returns any error writing the entry.
+func (l *Logger) TryWarn(msg ...interface{}) error {
+	if !l.enabled(WarnLvl) {
+		return nil
+	}
+	err := l.tryLog(WarnLvl, msg...)
+	l.toSentry(l.joinln(msg...), []interface{}{}, WarnLvl, nil)
+	return err
+}
+
+// TryErrorf is like Errorf, but returns any error writing the entry.
+func (l *Logger) TryErrorf(format string, msg ...interface{}) error {
+	if !l.enabled(ErrorLvl) {
+		return nil
+	}
+	err := l.tryLogf(format, ErrorLvl, msg...)
+	l.toSentry(format, msg, ErrorLvl, nil)
+	return err
+}
+
+// TryError is like Error, but returns any error writing the entry.
+func (l *Logger) TryError(msg ...interface{}) error {
+	if !l.enabled(ErrorLvl) {
+		return nil
+	}
+	err := l.tryLog(ErrorLvl, msg...)
+	l.toSentry(l.joinln(msg...), []interface{}{}, ErrorLvl, nil)
+	return err
+}
+
+func (l *Logger) tryLog(lvl Level, msg ...interface{}) error {
+	return l.output(l.calldepth+3, l.join(msg...), lvl)
+}
+
+func (l *Logger) tryLogf(format string, lvl Level, msg ...interface{}) error {
+	return l.output(l.calldepth+3, fmt.Sprintf(format, msg...), lvl)
+}
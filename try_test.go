@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTryMethods(t *testing.T) {
+	writeErr := errors.New("disk full")
+	log, err := New(InfoLvl, errWriter{err: writeErr}, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	log = log.SetErrorHandler(func(err error) {
+		t.Errorf("Expected error handler not to be called, got %+v\n", err)
+	})
+	if err := log.TryDebug("suppressed"); err != nil {
+		t.Errorf("Expected suppressed entry to return nil, got %+v\n", err)
+	}
+	for _, f := range []func(...interface{}) error{log.TryInfo, log.TryWarn, log.TryError} {
+		if err := f("Test output"); err != writeErr {
+			t.Errorf("Expected error to be %+v, got %+v instead\n", writeErr, err)
+		}
+	}
+	for _, ff := range []func(string, ...interface{}) error{log.TryInfof, log.TryWarnf, log.TryErrorf} {
+		if err := ff("Test %s", "output"); err != writeErr {
+			t.Errorf("Expected error to be %+v, got %+v instead\n", writeErr, err)
+		}
+	}
+}
+
+func TestTryCaller(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(DebugLvl, &buf, "", nil)
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if err := log.TryInfo("Test output"); err != nil {
+		t.Errorf("Unexpected error: %+v\n", err)
+	}
+	if !strings.Contains(buf.String(), "try_test.go:") {
+		t.Errorf("Expected caller to be try_test.go, got '%s'\n", buf.String())
+	}
+}
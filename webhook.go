@@ -0,0 +1,278 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWebhookMaxBatchSize = 64 << 10
+	defaultWebhookMaxBatchAge  = 5 * time.Second
+	defaultWebhookMaxRetries   = 3
+	defaultWebhookTimeout      = 10 * time.Second
+	webhookMinBackoff          = 500 * time.Millisecond
+	// webhookMaxQueued is the number of batches that can wait to be sent before new ones overflow.
+	webhookMaxQueued = 16
+)
+
+// WebhookConfig controls how a WebhookWriter batches and sends log output. The zero value is usable, and
+// gives the defaults described on each field.
+type WebhookConfig struct {
+	// Client is used to make requests. It defaults to a client with a ten second timeout, so a hung
+	// endpoint can't block Flush and Close forever.
+	Client *http.Client
+	// ContentType is sent as the Content-Type of each request. It defaults to "text/plain".
+	ContentType string
+	// Encode, if set, turns each batch of log lines into a request body, like SlackPayload does. By
+	// default the lines are sent as they are.
+	Encode func(batch []byte) []byte
+	// MaxBatchSize is the number of bytes a batch can hold before it is sent. It defaults to 64KB.
+	MaxBatchSize int
+	// MaxBatchAge is the longest a line waits in a batch before the batch is sent. It defaults to five
+	// seconds.
+	MaxBatchAge time.Duration
+	// MaxRetries is the number of times a failed request is retried, with exponential backoff, before the
+	// batch is given up on. It defaults to 3; set it to -1 to never retry.
+	MaxRetries int
+	// Fallback, if set, is written each batch that could not be sent, or that overflowed the queue of
+	// batches waiting to be sent, like os.Stderr.
+	Fallback io.Writer
+	// OnResponse, if set, is called with the body of each successful response, to check it for errors
+	// the endpoint reports despite a 2xx status.
+	OnResponse func(body []byte)
+}
+
+// webhookBatch is a batch of log lines waiting to be sent. If done is non-nil, it is closed once the
+// batch has been handled.
+type webhookBatch struct {
+	body []byte
+	done chan struct{}
+}
+
+// WebhookWriter is an io.WriteCloser that POSTs log output to an HTTP endpoint, like a Slack webhook or
+// an ingest API. Lines are collected into batches, which are sent when they reach the configured size
+// or age, from a background goroutine so logging never waits on the network. If the endpoint falls so
+// far behind that 16 batches are waiting to be sent, further batches are written to the Fallback, or
+// dropped if there is none, rather than making the caller wait. Only Flush and Close wait for batches to
+// be sent. It is safe for concurrent use. Close must be called to send the final batch and stop the
+// background goroutine.
+type WebhookWriter struct {
+	url    string
+	config WebhookConfig
+	sleep  func(time.Duration)
+
+	mu      sync.Mutex
+	batch   []byte
+	timer   *time.Timer
+	closed  bool
+	batches chan webhookBatch
+	stopped chan struct{}
+
+	fallbackMu sync.Mutex
+
+	errMu sync.Mutex
+	err   error
+}
+
+// NewWebhookWriter returns a WebhookWriter that sends batches of log lines to url, configured by config.
+func NewWebhookWriter(url string, config WebhookConfig) *WebhookWriter {
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+	if config.ContentType == "" {
+		config.ContentType = "text/plain"
+	}
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = defaultWebhookMaxBatchSize
+	}
+	if config.MaxBatchAge <= 0 {
+		config.MaxBatchAge = defaultWebhookMaxBatchAge
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultWebhookMaxRetries
+	}
+	w := &WebhookWriter{
+		url:     url,
+		config:  config,
+		sleep:   time.Sleep,
+		batches: make(chan webhookBatch, webhookMaxQueued),
+		stopped: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write adds p to the current batch, sending the batch if it is full.
+func (w *WebhookWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if len(w.batch) > 0 && len(w.batch)+len(p) > w.config.MaxBatchSize {
+		w.send(nil, false)
+	}
+	w.batch = append(w.batch, p...)
+	if len(w.batch) >= w.config.MaxBatchSize {
+		w.send(nil, false)
+	} else if w.timer == nil {
+		w.timer = time.AfterFunc(w.config.MaxBatchAge, w.expire)
+	}
+	return len(p), nil
+}
+
+// Flush sends the current batch, and waits for every batch to be handled. It returns the error of the
+// last batch that could not be sent since the previous Flush or Close, even if the batch was written to
+// the Fallback.
+func (w *WebhookWriter) Flush() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	done := make(chan struct{})
+	w.send(done, true)
+	w.mu.Unlock()
+	<-done
+	return w.takeErr()
+}
+
+// Close sends the current batch, waits for every batch to be handled, and stops the background
+// goroutine. Like Flush, it returns the error of the last batch that could not be sent.
+func (w *WebhookWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.send(nil, true)
+	w.closed = true
+	close(w.batches)
+	w.mu.Unlock()
+	<-w.stopped
+	return w.takeErr()
+}
+
+// takeErr returns the error of the last batch that could not be sent, and clears it.
+func (w *WebhookWriter) takeErr() error {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	err := w.err
+	w.err = nil
+	return err
+}
+
+// expire sends the current batch once it has reached MaxBatchAge.
+func (w *WebhookWriter) expire() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.send(nil, false)
+	}
+}
+
+// send queues the current batch for the background goroutine and starts a new one. done, if non-nil, is
+// closed once the queued batch has been handled, even if it was empty. Unless wait is set, send doesn't
+// wait for room in the queue; if it is full, the batch is written to the Fallback instead. The caller
+// must hold w.mu.
+func (w *WebhookWriter) send(done chan struct{}, wait bool) {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.batch) == 0 && done == nil {
+		return
+	}
+	b := webhookBatch{body: w.batch, done: done}
+	w.batch = nil
+	if wait {
+		w.batches <- b
+		return
+	}
+	select {
+	case w.batches <- b:
+	default:
+		w.fallback(b.body)
+	}
+}
+
+// run sends batches until the batches channel is closed.
+func (w *WebhookWriter) run() {
+	defer close(w.stopped)
+	for b := range w.batches {
+		if len(b.body) > 0 {
+			if err := w.post(b.body); err != nil {
+				w.errMu.Lock()
+				w.err = err
+				w.errMu.Unlock()
+			}
+		}
+		if b.done != nil {
+			close(b.done)
+		}
+	}
+}
+
+// post sends batch to the endpoint, retrying with backoff. If every attempt fails, it writes the batch
+// to the Fallback and returns the last attempt's error.
+func (w *WebhookWriter) post(batch []byte) error {
+	body := batch
+	if w.config.Encode != nil {
+		body = w.config.Encode(batch)
+	}
+	backoff := webhookMinBackoff
+	for attempt := 0; ; attempt++ {
+		err := w.postOnce(body)
+		if err == nil {
+			return nil
+		}
+		if attempt >= w.config.MaxRetries {
+			w.fallback(batch)
+			return err
+		}
+		w.sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// fallback writes batch to the Fallback, if there is one. Batches can overflow while the background
+// goroutine is writing a failed one, so writes are serialized.
+func (w *WebhookWriter) fallback(batch []byte) {
+	if w.config.Fallback == nil {
+		return
+	}
+	w.fallbackMu.Lock()
+	defer w.fallbackMu.Unlock()
+	w.config.Fallback.Write(batch)
+}
+
+// postOnce makes a single request to the endpoint with body.
+func (w *WebhookWriter) postOnce(body []byte) error {
+	resp, err := w.config.Client.Post(w.url, w.config.ContentType, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	if err == nil && w.config.OnResponse != nil {
+		w.config.OnResponse(respBody)
+	}
+	return nil
+}
+
+// SlackPayload encodes batch as the body of a Slack incoming webhook request, with the log lines as the
+// message text. Use it as a WebhookConfig's Encode function, with a ContentType of "application/json".
+func SlackPayload(batch []byte) []byte {
+	body := make([]byte, 0, len(batch)+16)
+	body = append(body, `{"text":`...)
+	appendJSONString(&body, string(batch))
+	return append(body, '}')
+}
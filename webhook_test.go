@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookServer records the bodies POSTed to it, failing the first failures requests.
+type webhookServer struct {
+	mu       sync.Mutex
+	bodies   []string
+	failures int
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	s.bodies = append(s.bodies, string(body))
+}
+
+func (s *webhookServer) all() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func TestWebhookWriterBatchesBySize(t *testing.T) {
+	recorder := &webhookServer{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+	w := NewWebhookWriter(server.URL, WebhookConfig{MaxBatchSize: 10, MaxBatchAge: time.Hour})
+	w.Write([]byte("aaaa\n"))
+	w.Write([]byte("bbbb\n"))
+	w.Write([]byte("cccc\n"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	bodies := recorder.all()
+	if len(bodies) != 2 || bodies[0] != "aaaa\nbbbb\n" || bodies[1] != "cccc\n" {
+		t.Errorf("Expected two batches, got %q instead\n", bodies)
+	}
+	if _, err := w.Write([]byte("late\n")); err == nil {
+		t.Error("Expected an error writing after Close")
+	}
+}
+
+func TestWebhookWriterTimeout(t *testing.T) {
+	w := NewWebhookWriter("http://localhost", WebhookConfig{})
+	defer w.Close()
+	if w.config.Client.Timeout != defaultWebhookTimeout {
+		t.Errorf("Expected a default timeout of %s, got %s instead\n", defaultWebhookTimeout, w.config.Client.Timeout)
+	}
+}
+
+func TestWebhookWriterBatchesByAge(t *testing.T) {
+	recorder := &webhookServer{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+	w := NewWebhookWriter(server.URL, WebhookConfig{MaxBatchAge: 10 * time.Millisecond})
+	defer w.Close()
+	w.Write([]byte("aged\n"))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(recorder.all()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if bodies := recorder.all(); len(bodies) != 1 || bodies[0] != "aged\n" {
+		t.Errorf("Expected the batch to be sent once it aged, got %q instead\n", bodies)
+	}
+}
+
+func TestWebhookWriterRetryAndFallback(t *testing.T) {
+	recorder := &webhookServer{failures: 2}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+	var fallback bytes.Buffer
+	var slept []time.Duration
+	w := NewWebhookWriter(server.URL, WebhookConfig{MaxRetries: 2, Fallback: &fallback})
+	w.sleep = func(d time.Duration) { slept = append(slept, d) }
+	defer w.Close()
+
+	w.Write([]byte("retried\n"))
+	if err := w.Flush(); err != nil {
+		t.Errorf("Unexpected error: %+v\n", err)
+	}
+	if bodies := recorder.all(); len(bodies) != 1 || bodies[0] != "retried\n" {
+		t.Errorf("Expected the batch to be sent after retrying, got %q instead\n", bodies)
+	}
+	if len(slept) != 2 || slept[0] != webhookMinBackoff || slept[1] != 2*webhookMinBackoff {
+		t.Errorf("Expected exponential backoff, got %v instead\n", slept)
+	}
+
+	recorder.mu.Lock()
+	recorder.failures = 3
+	recorder.mu.Unlock()
+	w.Write([]byte("lost\n"))
+	if err := w.Flush(); err == nil {
+		t.Error("Expected an error flushing a batch that could not be sent, got nil instead")
+	}
+	if err := w.Flush(); err != nil {
+		t.Errorf("Expected the error to be reported once, got %+v instead\n", err)
+	}
+	if fallback.String() != "lost\n" {
+		t.Errorf("Expected the failed batch in the fallback, got '%s' instead\n", fallback.String())
+	}
+}
+
+func TestWebhookWriterOverflow(t *testing.T) {
+	recorder := &webhookServer{}
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		recorder.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	var fallback bytes.Buffer
+	w := NewWebhookWriter(server.URL, WebhookConfig{MaxBatchSize: 1, Fallback: &fallback})
+
+	written := make(chan struct{})
+	go func() {
+		for i := 0; i < webhookMaxQueued+4; i++ {
+			w.Write([]byte("x\n"))
+		}
+		close(written)
+	}()
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Write not to wait for the endpoint")
+	}
+	close(release)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	sent, overflowed := len(recorder.all()), bytes.Count(fallback.Bytes(), []byte("x\n"))
+	if overflowed == 0 || sent+overflowed != webhookMaxQueued+4 {
+		t.Errorf("Expected the overflow in the fallback, got %d sent and %d in the fallback instead\n", sent, overflowed)
+	}
+}
+
+func TestSlackPayload(t *testing.T) {
+	var payload struct{ Text string }
+	if err := json.Unmarshal(SlackPayload([]byte("an \"error\"\n")), &payload); err != nil {
+		t.Fatalf("Unexpected error: %+v\n", err)
+	}
+	if payload.Text != "an \"error\"\n" {
+		t.Errorf("Expected text to be %q, got %q instead\n", "an \"error\"\n", payload.Text)
+	}
+}